@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"strings"
+)
+
+type Permission string
+
+const (
+	Read  Permission = "read"
+	Write Permission = "write"
+
+	// Admin implies both the `read` and `write` permissions.
+	Admin Permission = "admin"
+)
+
+// AnyPrincipal grants permissions to every authenticated principal.
+const AnyPrincipal = "*"
+
+// Grant gives permissions to a principal on the streams starting with the prefix.
+type Grant struct {
+	Principal   string       `json:"principal"`
+	Prefix      string       `json:"prefix"`
+	Permissions []Permission `json:"permissions"`
+}
+
+type ACL []Grant
+
+// Allows checks whether the principal has the permission on every stream starting with the
+// prefix. For a single stream, the prefix is the stream name.
+func (acl ACL) Allows(principal string, permission Permission, prefix string) bool {
+	for _, grant := range acl {
+		if grant.Principal != principal && grant.Principal != AnyPrincipal {
+			continue
+		}
+
+		if !strings.HasPrefix(prefix, grant.Prefix) {
+			continue
+		}
+
+		for _, p := range grant.Permissions {
+			if p == permission || p == Admin {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+)
+
+// APIKeyAuthenticator identifies principals by static API keys.
+type APIKeyAuthenticator struct {
+	// principals are the names of the principals, by API key.
+	principals map[string]string
+}
+
+func NewAPIKeyAuthenticator(principals map[string]string) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{
+		principals: principals,
+	}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(_ context.Context, credentials Credentials) (*Principal, error) {
+	if credentials.APIKey == "" {
+		return nil, nil
+	}
+
+	// Every key is compared, in constant time, not to leak which keys exist.
+	var name string
+	for key, principal := range a.principals {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(credentials.APIKey)) == 1 {
+			name = principal
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("unknown API key")
+	}
+
+	return &Principal{Name: name, Method: "api-key"}, nil
+}
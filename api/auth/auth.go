@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+)
+
+// Principal is the authenticated identity of a caller, to which the grants apply.
+type Principal struct {
+	Name string
+
+	// Method is the authentication method which identified the principal (e.g. `api-key`).
+	Method string
+}
+
+// Credentials are the credentials presented by a caller, over gRPC or HTTP.
+type Credentials struct {
+	// BearerToken is the token of the `Authorization: Bearer` header.
+	BearerToken string
+
+	// APIKey is the value of the `X-API-Key` header.
+	APIKey string
+
+	// VerifiedChains are the client certificate chains verified by the TLS handshake.
+	VerifiedChains [][]*x509.Certificate
+}
+
+const (
+	AuthorizationHeader = "Authorization"
+	APIKeyHeader        = "X-API-Key"
+)
+
+var ErrNoCredentials = errors.New("no credentials were presented")
+
+// Authenticator identifies the principal from the credentials. It returns no principal and no
+// error when the credentials it expects are not presented, so that the next authenticator
+// is tried.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credentials Credentials) (*Principal, error)
+}
+
+// Chain tries the authenticators in order. The first identified principal, or the first error,
+// is returned.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, credentials Credentials) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credentials)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+
+	return nil, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal authenticated for the context, if any.
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return principal
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func Test_ACL(t *testing.T) {
+	acl := ACL{
+		{Principal: "orders", Prefix: "Orders/", Permissions: []Permission{Read, Write}},
+		{Principal: "ops", Prefix: "", Permissions: []Permission{Admin}},
+		{Principal: AnyPrincipal, Prefix: "Public/", Permissions: []Permission{Read}},
+	}
+
+	t.Run("grants permissions on the streams starting with the prefix", func(t *testing.T) {
+		assert.True(t, acl.Allows("orders", Write, "Orders/123"))
+		assert.True(t, acl.Allows("orders", Read, "Orders/"))
+		assert.False(t, acl.Allows("orders", Read, "Payments/123"))
+		assert.False(t, acl.Allows("orders", Admin, "Orders/123"))
+	})
+
+	t.Run("does not grant a query on a wider prefix", func(t *testing.T) {
+		assert.False(t, acl.Allows("orders", Read, "Ord"))
+		assert.False(t, acl.Allows("orders", Read, ""))
+	})
+
+	t.Run("admin implies read and write", func(t *testing.T) {
+		assert.True(t, acl.Allows("ops", Read, "Orders/123"))
+		assert.True(t, acl.Allows("ops", Write, "Payments/123"))
+	})
+
+	t.Run("grants to any principal", func(t *testing.T) {
+		assert.True(t, acl.Allows("someone", Read, "Public/news"))
+		assert.False(t, acl.Allows("someone", Write, "Public/news"))
+	})
+}
+
+func Test_Authenticators(t *testing.T) {
+	ctx := context.Background()
+	apiKeys := NewAPIKeyAuthenticator(map[string]string{"secret": "orders"})
+	certificates := NewCertificateAuthenticator()
+
+	t.Run("identifies principals with their API key", func(t *testing.T) {
+		principal, err := apiKeys.Authenticate(ctx, Credentials{APIKey: "secret"})
+		assert.Nil(t, err)
+		assert.Equal(t, &Principal{Name: "orders", Method: "api-key"}, principal)
+
+		_, err = apiKeys.Authenticate(ctx, Credentials{APIKey: "unknown"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("identifies principals with their client certificate", func(t *testing.T) {
+		principal, err := certificates.Authenticate(ctx, Credentials{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "payments"}}}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "payments", principal.Name)
+	})
+
+	t.Run("chain tries the authenticators for which credentials are presented", func(t *testing.T) {
+		chain := Chain{certificates, apiKeys}
+
+		principal, err := chain.Authenticate(ctx, Credentials{APIKey: "secret"})
+		assert.Nil(t, err)
+		assert.Equal(t, "orders", principal.Name)
+
+		_, err = chain.Authenticate(ctx, Credentials{})
+		assert.Equal(t, ErrNoCredentials, err)
+	})
+}
+
+func Test_Authorizer(t *testing.T) {
+	audit := &bytes.Buffer{}
+	authorizer := NewAuthorizer(
+		NewAPIKeyAuthenticator(map[string]string{"secret": "orders"}),
+		ACL{{Principal: "orders", Prefix: "Orders/", Permissions: []Permission{Write}}},
+	)
+	authorizer.SetAuditLog(audit)
+
+	lastAuditEntry := func() AuditEntry {
+		lines := bytes.Split(bytes.TrimSpace(audit.Bytes()), []byte("\n"))
+		entry := AuditEntry{}
+		assert.Nil(t, json.Unmarshal(lines[len(lines)-1], &entry))
+
+		return entry
+	}
+
+	principal, err := authorizer.Authenticate(context.Background(), "/fossil.Writer/Append", Credentials{APIKey: "secret"})
+	assert.Nil(t, err)
+	ctx := WithPrincipal(context.Background(), principal)
+
+	t.Run("allows granted calls", func(t *testing.T) {
+		assert.Nil(t, authorizer.Authorize(ctx, "/fossil.Writer/Append", Write, "Orders/123"))
+		assert.Equal(t, 0, audit.Len())
+	})
+
+	t.Run("denies and audits calls without the permission", func(t *testing.T) {
+		err := authorizer.Authorize(ctx, "/fossil.Writer/ReadStream", Read, "Orders/123")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		entry := lastAuditEntry()
+		assert.Equal(t, "orders", entry.Principal)
+		assert.Equal(t, "/fossil.Writer/ReadStream", entry.Method)
+		assert.Equal(t, Read, entry.Permission)
+		assert.Equal(t, "Orders/123", entry.Resource)
+	})
+
+	t.Run("rejects and audits invalid credentials", func(t *testing.T) {
+		_, err := authorizer.Authenticate(context.Background(), "/fossil.Writer/Append", Credentials{APIKey: "unknown"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		assert.Equal(t, "/fossil.Writer/Append", lastAuditEntry().Method)
+
+		err = authorizer.Authorize(context.Background(), "/fossil.Writer/Append", Write, "Orders/123")
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
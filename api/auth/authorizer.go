@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// Authorizer authenticates the callers and checks their permissions against the ACL. Denied
+// calls are recorded in the audit log.
+type Authorizer struct {
+	authenticator Authenticator
+	acl           ACL
+	audit         *log.Logger
+}
+
+func NewAuthorizer(authenticator Authenticator, acl ACL) *Authorizer {
+	return &Authorizer{
+		authenticator: authenticator,
+		acl:           acl,
+		audit:         log.New(os.Stderr, "", 0),
+	}
+}
+
+// SetAuditLog sets where the audit entries, one JSON document per line, are written.
+func (a *Authorizer) SetAuditLog(w io.Writer) {
+	a.audit = log.New(w, "", 0)
+}
+
+// AuditEntry is a line of the audit log.
+type AuditEntry struct {
+	Time       time.Time  `json:"time"`
+	Principal  string     `json:"principal,omitempty"`
+	Method     string     `json:"method"`
+	Permission Permission `json:"permission,omitempty"`
+	Resource   string     `json:"resource,omitempty"`
+	Reason     string     `json:"reason"`
+}
+
+// Authenticate identifies the principal presenting the credentials, for the call of the method.
+func (a *Authorizer) Authenticate(ctx context.Context, method string, credentials Credentials) (*Principal, error) {
+	principal, err := a.authenticator.Authenticate(ctx, credentials)
+	if err != nil {
+		a.record(AuditEntry{Method: method, Reason: err.Error()})
+
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %s", err)
+	}
+
+	return principal, nil
+}
+
+// Authorize checks that the principal of the context has the permission on the streams
+// starting with the prefix.
+func (a *Authorizer) Authorize(ctx context.Context, method string, permission Permission, prefix string) error {
+	principal := PrincipalFrom(ctx)
+	if principal == nil {
+		a.record(AuditEntry{Method: method, Permission: permission, Resource: prefix, Reason: "unauthenticated"})
+
+		return status.Error(codes.Unauthenticated, "authentication is required")
+	}
+
+	if !a.acl.Allows(principal.Name, permission, prefix) {
+		a.record(AuditEntry{Principal: principal.Name, Method: method, Permission: permission, Resource: prefix, Reason: "permission denied"})
+
+		return status.Errorf(codes.PermissionDenied, "%q does not have the %s permission on %q", principal.Name, permission, prefix)
+	}
+
+	return nil
+}
+
+func (a *Authorizer) record(entry AuditEntry) {
+	entry.Time = time.Now().UTC()
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("unable to write audit entry: %s", err)
+		return
+	}
+
+	a.audit.Println(string(b))
+}
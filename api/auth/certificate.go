@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+)
+
+// CertificateAuthenticator identifies principals by the common name of their client
+// certificate. The certificate must have been verified by the TLS handshake, against the
+// server's client CA.
+type CertificateAuthenticator struct{}
+
+func NewCertificateAuthenticator() *CertificateAuthenticator {
+	return &CertificateAuthenticator{}
+}
+
+func (a *CertificateAuthenticator) Authenticate(_ context.Context, credentials Credentials) (*Principal, error) {
+	if len(credentials.VerifiedChains) == 0 || len(credentials.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+
+	commonName := credentials.VerifiedChains[0][0].Subject.CommonName
+	if commonName == "" {
+		return nil, nil
+	}
+
+	return &Principal{Name: commonName, Method: "client-certificate"}, nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config is the JSON configuration of the authentication methods and of the ACL.
+type Config struct {
+	// APIKeys are the names of the principals, by API key.
+	APIKeys map[string]string `json:"api_keys"`
+
+	// ClientCertificates enables the authentication with the TLS client certificates.
+	ClientCertificates bool `json:"client_certificates"`
+
+	JWT *JWTConfig `json:"jwt"`
+
+	Grants []Grant `json:"grants"`
+
+	// AuditLog is the file to which the audit entries are appended. They are written to the
+	// standard error when empty.
+	AuditLog string `json:"audit_log"`
+}
+
+type JWTConfig struct {
+	JWKSFile string `json:"jwks_file"`
+	Issuer   string `json:"issuer"`
+	Audience string `json:"audience"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &Config{}
+	if err := json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+// NewAuthorizerFromConfig creates the authorizer, trying the client certificates, the JWTs and
+// the API keys, in this order.
+func NewAuthorizerFromConfig(config *Config) (*Authorizer, error) {
+	var chain Chain
+	if config.ClientCertificates {
+		chain = append(chain, NewCertificateAuthenticator())
+	}
+
+	if config.JWT != nil {
+		authenticator, err := NewJWTAuthenticatorFromFile(config.JWT.JWKSFile, config.JWT.Issuer, config.JWT.Audience)
+		if err != nil {
+			return nil, err
+		}
+
+		chain = append(chain, authenticator)
+	}
+
+	if len(config.APIKeys) > 0 {
+		chain = append(chain, NewAPIKeyAuthenticator(config.APIKeys))
+	}
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no authentication method is configured")
+	}
+
+	for _, grant := range config.Grants {
+		for _, permission := range grant.Permissions {
+			if permission != Read && permission != Write && permission != Admin {
+				return nil, fmt.Errorf("unknown permission %q granted to %q", permission, grant.Principal)
+			}
+		}
+	}
+
+	authorizer := NewAuthorizer(chain, config.Grants)
+	if config.AuditLog != "" {
+		f, err := os.OpenFile(config.AuditLog, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open audit log: %w", err)
+		}
+
+		authorizer.SetAuditLog(f)
+	}
+
+	return authorizer, nil
+}
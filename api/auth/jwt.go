@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTAuthenticator identifies principals by the `sub` claim of JWTs, signed by one of the keys
+// of a JWKS (RS256, RS384, RS512, ES256, ES384 or ES512).
+type JWTAuthenticator struct {
+	keys     map[string]crypto.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticatorFromFile reads the JWKS file. The issuer and audience claims are only
+// checked when not empty.
+func NewJWTAuthenticatorFromFile(jwksPath string, issuer string, audience string) (*JWTAuthenticator, error) {
+	b, err := os.ReadFile(jwksPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read JWKS: %w", err)
+	}
+
+	keys, err := parseJWKS(b)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse JWKS: %w", err)
+	}
+
+	return &JWTAuthenticator{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}, nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyId     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+func (a *JWTAuthenticator) Authenticate(_ context.Context, credentials Credentials) (*Principal, error) {
+	if credentials.BearerToken == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(credentials.BearerToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed JWT")
+	}
+
+	header := jwtHeader{}
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed JWT header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT signature: %w", err)
+	}
+
+	err = a.verify(header, []byte(parts[0]+"."+parts[1]), signature)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwtClaims{}
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+
+	now := a.now().Unix()
+	if claims.ExpiresAt == nil || now >= *claims.ExpiresAt {
+		return nil, fmt.Errorf("JWT is expired")
+	} else if claims.NotBefore != nil && now < *claims.NotBefore {
+		return nil, fmt.Errorf("JWT is not valid yet")
+	} else if a.issuer != "" && claims.Issuer != a.issuer {
+		return nil, fmt.Errorf("JWT issuer %q is not trusted", claims.Issuer)
+	} else if a.audience != "" && !hasAudience(claims.Audience, a.audience) {
+		return nil, fmt.Errorf("JWT is not intended for audience %q", a.audience)
+	} else if claims.Subject == "" {
+		return nil, fmt.Errorf("JWT has no subject")
+	}
+
+	return &Principal{Name: claims.Subject, Method: "jwt"}, nil
+}
+
+func (a *JWTAuthenticator) verify(header jwtHeader, signed []byte, signature []byte) error {
+	key, found := a.keys[header.KeyId]
+	if !found {
+		return fmt.Errorf("JWT is signed with unknown key %q", header.KeyId)
+	}
+
+	var hash crypto.Hash
+	switch header.Algorithm {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("JWT algorithm %q is not supported", header.Algorithm)
+	}
+
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(header.Algorithm, "RS") {
+			return fmt.Errorf("JWT algorithm %q does not match the RSA key", header.Algorithm)
+		}
+
+		if err := rsa.VerifyPKCS1v15(k, hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(header.Algorithm, "ES") {
+			return fmt.Errorf("JWT algorithm %q does not match the EC key", header.Algorithm)
+		}
+
+		// The signature is the concatenation of `r` and `s`.
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid JWT signature")
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return fmt.Errorf("invalid JWT signature")
+		}
+	}
+
+	return nil
+}
+
+func decodeJWTPart(part string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// hasAudience checks the `aud` claim, which is either a string or an array of strings.
+func hasAudience(claim json.RawMessage, audience string) bool {
+	var single string
+	if json.Unmarshal(claim, &single) == nil {
+		return single == audience
+	}
+
+	var multiple []string
+	if json.Unmarshal(claim, &multiple) == nil {
+		for _, a := range multiple {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+type jwk struct {
+	KeyType string `json:"kty"`
+	KeyId   string `json:"kid"`
+
+	// RSA keys.
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC keys.
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+func parseJWKS(b []byte) (map[string]crypto.PublicKey, error) {
+	set := jwks{}
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.KeyId, err)
+		}
+
+		keys[k.KeyId] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %q is not supported", k.Curve)
+		}
+
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("key type %q is not supported", k.KeyType)
+	}
+}
+
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
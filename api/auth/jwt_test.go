@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_JWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	jwksPath := filepath.Join(t.TempDir(), "jwks.json")
+	jwksContents, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(jwksPath, jwksContents, 0600))
+
+	authenticator, err := NewJWTAuthenticatorFromFile(jwksPath, "https://issuer.example", "fossil")
+	assert.Nil(t, err)
+
+	sign := func(alg string, kid string, claims map[string]interface{}) string {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+		payload, _ := json.Marshal(claims)
+		signed := encode(header) + "." + encode(payload)
+		digest := sha256.Sum256([]byte(signed))
+
+		var signature []byte
+		if alg == "RS256" {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+			assert.Nil(t, err)
+		} else {
+			r, s, err := ecdsa.Sign(rand.Reader, ecKey, digest[:])
+			assert.Nil(t, err)
+			signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+
+		return signed + "." + encode(signature)
+	}
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "orders",
+			"iss": "https://issuer.example",
+			"aud": []string{"fossil"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	authenticate := func(token string) (*Principal, error) {
+		return authenticator.Authenticate(context.Background(), Credentials{BearerToken: token})
+	}
+
+	t.Run("identifies the subject of tokens signed with RSA and EC keys", func(t *testing.T) {
+		principal, err := authenticate(sign("RS256", "rsa", validClaims()))
+		assert.Nil(t, err)
+		assert.Equal(t, &Principal{Name: "orders", Method: "jwt"}, principal)
+
+		principal, err = authenticate(sign("ES256", "ec", validClaims()))
+		assert.Nil(t, err)
+		assert.Equal(t, "orders", principal.Name)
+	})
+
+	t.Run("ignores requests without token", func(t *testing.T) {
+		principal, err := authenticate("")
+		assert.Nil(t, err)
+		assert.Nil(t, principal)
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		expired := validClaims()
+		expired["exp"] = time.Now().Add(-time.Minute).Unix()
+		_, err := authenticate(sign("RS256", "rsa", expired))
+		assert.ErrorContains(t, err, "expired")
+
+		otherAudience := validClaims()
+		otherAudience["aud"] = "another-service"
+		_, err = authenticate(sign("RS256", "rsa", otherAudience))
+		assert.ErrorContains(t, err, "audience")
+
+		otherIssuer := validClaims()
+		otherIssuer["iss"] = "https://attacker.example"
+		_, err = authenticate(sign("RS256", "rsa", otherIssuer))
+		assert.ErrorContains(t, err, "issuer")
+
+		_, err = authenticate(sign("RS256", "unknown", validClaims()))
+		assert.ErrorContains(t, err, "unknown key")
+
+		// Signed with the EC key, but claiming the RSA one.
+		_, err = authenticate(sign("ES256", "rsa", validClaims()))
+		assert.ErrorContains(t, err, "does not match")
+
+		token := sign("RS256", "rsa", validClaims())
+		_, err = authenticate(token[:len(token)-4] + "AAAA")
+		assert.ErrorContains(t, err, "signature")
+
+		_, err = authenticate("not-a-jwt")
+		assert.ErrorContains(t, err, "malformed")
+	})
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strings"
+)
+
+// UnaryServerInterceptor authenticates the callers of the unary RPCs.
+func (a *Authorizer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		principal, err := a.Authenticate(ctx, info.FullMethod, grpcCredentials(ctx))
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(WithPrincipal(ctx, principal), req)
+	}
+}
+
+// StreamServerInterceptor authenticates the callers of the streaming RPCs.
+func (a *Authorizer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		principal, err := a.Authenticate(ss.Context(), info.FullMethod, grpcCredentials(ss.Context()))
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{
+			ServerStream: ss,
+			ctx:          WithPrincipal(ss.Context(), principal),
+		})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// Middleware authenticates the callers of the HTTP handler.
+func (a *Authorizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := Credentials{
+			BearerToken: bearerToken(r.Header.Get(AuthorizationHeader)),
+			APIKey:      r.Header.Get(APIKeyHeader),
+		}
+		if r.TLS != nil {
+			c.VerifiedChains = r.TLS.VerifiedChains
+		}
+
+		principal, err := a.Authenticate(r.Context(), r.Method+" "+r.URL.Path, c)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": status.Convert(err).Message()})
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+	})
+}
+
+func grpcCredentials(ctx context.Context) Credentials {
+	c := Credentials{}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(strings.ToLower(AuthorizationHeader)); len(values) > 0 {
+			c.BearerToken = bearerToken(values[0])
+		}
+
+		if values := md.Get(strings.ToLower(APIKeyHeader)); len(values) > 0 {
+			c.APIKey = values[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			c.VerifiedChains = info.State.VerifiedChains
+		}
+	}
+
+	return c
+}
+
+func bearerToken(header string) string {
+	const prefix = "Bearer "
+	if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+		return header[len(prefix):]
+	}
+
+	return ""
+}
@@ -0,0 +1,251 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sroze/fossil/api/auth"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/store/segments"
+	"github.com/sroze/fossil/store/topology"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The admin RPCs operate on the whole topology: they require the admin permission on all the
+// streams.
+const adminPrefix = ""
+
+func (s *Server) ListSegments(ctx context.Context, _ *v1.ListSegmentsRequest) (*v1.ListSegmentsReply, error) {
+	err := s.authorize(ctx, v1.Admin_ListSegments_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := s.store.GetTopologyManager().GetState().GetSegments()
+	reply := &v1.ListSegmentsReply{
+		Segments: make([]*v1.Segment, len(nodes)),
+	}
+
+	for i, node := range nodes {
+		reply.Segments[i], err = s.segmentWithHead(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reply, nil
+}
+
+func (s *Server) CreateSegment(ctx context.Context, request *v1.CreateSegmentRequest) (*v1.CreateSegmentReply, error) {
+	err := s.authorize(ctx, v1.Admin_CreateSegment_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	segment, err := s.store.GetTopologyManager().Create(segments.NewSegment(
+		segments.NewPrefixRange(request.Prefix),
+	))
+	if err != nil {
+		return nil, topologyError(err)
+	}
+
+	return &v1.CreateSegmentReply{
+		Segment: segmentToProto(*segment),
+	}, nil
+}
+
+func (s *Server) SplitSegment(ctx context.Context, request *v1.SplitSegmentRequest) (*v1.SplitSegmentReply, error) {
+	err := s.authorize(ctx, v1.Admin_SplitSegment_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.ChunkCount < 1 {
+		return nil, status.Errorf(codes.InvalidArgument, "chunk count must be at least 1")
+	}
+
+	into, err := s.store.GetTopologyManager().Split(request.SegmentId, int(request.ChunkCount))
+	if err != nil {
+		return nil, topologyError(err)
+	}
+
+	reply := &v1.SplitSegmentReply{
+		Segments: make([]*v1.Segment, len(into)),
+	}
+	for i, segment := range into {
+		reply.Segments[i] = segmentToProto(segment)
+	}
+
+	return reply, nil
+}
+
+func (s *Server) ReplaceSegment(ctx context.Context, request *v1.ReplaceSegmentRequest) (*v1.ReplaceSegmentReply, error) {
+	err := s.authorize(ctx, v1.Admin_ReplaceSegment_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	replacement, err := s.store.GetTopologyManager().Replace(request.SegmentId)
+	if err != nil {
+		return nil, topologyError(err)
+	}
+
+	return &v1.ReplaceSegmentReply{
+		Segment: segmentToProto(*replacement),
+	}, nil
+}
+
+func (s *Server) GetStreamLocation(ctx context.Context, request *v1.GetStreamLocationRequest) (*v1.GetStreamLocationReply, error) {
+	err := s.authorize(ctx, v1.Admin_GetStreamLocation_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := s.store.GetTopologyManager().GetState().GetStreamLocation(request.StreamName)
+	if err != nil {
+		return nil, topologyError(err)
+	}
+
+	reply := &v1.GetStreamLocationReply{
+		ReadSegments: make([]*v1.Segment, len(location.ReadSegments)),
+	}
+
+	// The segment written into is the last one of the reading DAG.
+	for i, node := range location.ReadSegments {
+		reply.ReadSegments[i], err = s.segmentWithHead(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+
+		if location.WriteSegment != nil && node.Segment.Id == location.WriteSegment.Id {
+			reply.WriteSegment = reply.ReadSegments[i]
+		}
+	}
+
+	return reply, nil
+}
+
+func (s *Server) WatchTopology(request *v1.WatchTopologyRequest, server v1.Admin_WatchTopologyServer) error {
+	err := s.authorize(server.Context(), v1.Admin_WatchTopology_FullMethodName, auth.Admin, adminPrefix)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(server.Context())
+	defer cancel()
+
+	ch := make(chan topology.WatchedEvent)
+	go s.store.GetTopologyManager().Watch(ctx, request.StartingPosition, ch)
+
+	for {
+		select {
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case item, more := <-ch:
+			if !more {
+				return ctx.Err()
+			}
+
+			if item.Error != nil {
+				return fmt.Errorf("error while watching the topology: %w", item.Error)
+			}
+
+			event := topologyEventToProto(item)
+			if event == nil {
+				continue
+			}
+
+			err := server.Send(event)
+			if err != nil {
+				return fmt.Errorf("error while sending topology event: %w", err)
+			}
+		}
+	}
+}
+
+// segmentWithHead returns the segment, with its head position.
+func (s *Server) segmentWithHead(ctx context.Context, node topology.SegmentNode) (*v1.Segment, error) {
+	head, closed, err := s.store.GetTopologyManager().GetSegmentHead(ctx, node.Segment.Id)
+	if err != nil {
+		return nil, fmt.Errorf("could not get head of segment %s: %w", node.Segment.ID(), err)
+	}
+
+	segment := segmentToProto(node.Segment)
+	segment.Parents = node.Parents
+	segment.Children = node.Children
+	segment.Closed = closed || len(node.Children) > 0
+	segment.HeadPosition = head
+
+	return segment, nil
+}
+
+func topologyError(err error) error {
+	if errors.As(err, &topology.SegmentNotFoundError{}) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	return err
+}
+
+func segmentToProto(segment segments.Segment) *v1.Segment {
+	return &v1.Segment{
+		Id:           segment.ID(),
+		Range:        streamRangeToProto(segment.StreamRange),
+		HeadPosition: -1,
+	}
+}
+
+func streamRangeToProto(r segments.StreamRange) *v1.StreamRange {
+	switch t := r.(type) {
+	case segments.PrefixRange:
+		return &v1.StreamRange{Range: &v1.StreamRange_Prefix{Prefix: &v1.PrefixRange{Prefix: t.Prefix}}}
+	case segments.HashSplitRange:
+		return &v1.StreamRange{Range: &v1.StreamRange_HashSplit{HashSplit: &v1.HashSplitRange{
+			AssignedPartition: int64(t.AssignedPartition),
+			PartitionCount:    int64(t.PartitionCount),
+			Seed:              t.Seed,
+		}}}
+	case segments.ComposedRange:
+		ranges := make([]*v1.StreamRange, len(t.StreamRanges))
+		for i, sr := range t.StreamRanges {
+			ranges[i] = streamRangeToProto(sr)
+		}
+
+		return &v1.StreamRange{Range: &v1.StreamRange_Composed{Composed: &v1.ComposedRange{Ranges: ranges}}}
+	default:
+		return nil
+	}
+}
+
+// topologyEventToProto returns the event of the topology stream, or nil for the events which
+// are not about segments.
+func topologyEventToProto(item topology.WatchedEvent) *v1.TopologyEvent {
+	event := &v1.TopologyEvent{Position: item.Position}
+
+	switch e := item.Event.(type) {
+	case *topology.SegmentCreatedEvent:
+		event.Event = &v1.TopologyEvent_SegmentCreated{SegmentCreated: &v1.SegmentCreated{
+			Segment: segmentToProto(e.Segment),
+		}}
+	case *topology.SegmentSplitEvent:
+		into := make([]*v1.Segment, len(e.Into))
+		for i, segment := range e.Into {
+			into[i] = segmentToProto(segment)
+		}
+
+		event.Event = &v1.TopologyEvent_SegmentSplit{SegmentSplit: &v1.SegmentSplit{
+			SegmentId: e.SegmentId.String(),
+			Into:      into,
+		}}
+	case *topology.SegmentReplacedEvent:
+		event.Event = &v1.TopologyEvent_SegmentReplaced{SegmentReplaced: &v1.SegmentReplaced{
+			SegmentId:  e.SegmentId.String(),
+			ReplacedBy: segmentToProto(e.ReplacedBy),
+		}}
+	default:
+		return nil
+	}
+
+	return event
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"github.com/google/uuid"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func Test_Admin(t *testing.T) {
+	err, server, addr := NewServer(testStore(), Config{})
+	assert.Nil(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	admin := v1.NewAdminClient(conn)
+	writer := v1.NewWriterClient(conn)
+	ctx := context.Background()
+
+	stream := "Foo/" + uuid.NewString()
+	_, err = writer.Append(ctx, &v1.AppendRequest{
+		StreamName: stream,
+		Events:     []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+	})
+	assert.Nil(t, err)
+
+	segments, err := admin.ListSegments(ctx, &v1.ListSegmentsRequest{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(segments.Segments))
+	root := segments.Segments[0]
+
+	t.Run("lists the segments, with their range and head", func(t *testing.T) {
+		assert.Equal(t, "", root.Range.GetPrefix().Prefix)
+		assert.False(t, root.Closed)
+		assert.Equal(t, int64(0), root.HeadPosition)
+		assert.Equal(t, 0, len(root.Parents))
+	})
+
+	t.Run("locates a stream", func(t *testing.T) {
+		location, err := admin.GetStreamLocation(ctx, &v1.GetStreamLocationRequest{StreamName: stream})
+		assert.Nil(t, err)
+		assert.Equal(t, root.Id, location.WriteSegment.Id)
+		assert.Equal(t, 1, len(location.ReadSegments))
+	})
+
+	t.Run("splits and replaces segments, which are then closed", func(t *testing.T) {
+		split, err := admin.SplitSegment(ctx, &v1.SplitSegmentRequest{SegmentId: root.Id, ChunkCount: 2})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(split.Segments))
+		assert.Equal(t, 2, len(split.Segments[0].Range.GetComposed().Ranges))
+
+		replaced, err := admin.ReplaceSegment(ctx, &v1.ReplaceSegmentRequest{SegmentId: split.Segments[0].Id})
+		assert.Nil(t, err)
+		assert.Equal(t, split.Segments[0].Range.GetComposed().Ranges[1].GetHashSplit().AssignedPartition, replaced.Segment.Range.GetComposed().Ranges[1].GetHashSplit().AssignedPartition)
+
+		segments, err := admin.ListSegments(ctx, &v1.ListSegmentsRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 4, len(segments.Segments))
+		for _, segment := range segments.Segments {
+			if segment.Id == root.Id {
+				assert.True(t, segment.Closed)
+				assert.ElementsMatch(t, []string{split.Segments[0].Id, split.Segments[1].Id}, segment.Children)
+			} else if segment.Id == replaced.Segment.Id {
+				assert.False(t, segment.Closed)
+				assert.Equal(t, []string{split.Segments[0].Id}, segment.Parents)
+			}
+		}
+
+		_, err = admin.ReplaceSegment(ctx, &v1.ReplaceSegmentRequest{SegmentId: root.Id})
+		assert.NotNil(t, err)
+
+		_, err = admin.SplitSegment(ctx, &v1.SplitSegmentRequest{SegmentId: uuid.NewString(), ChunkCount: 2})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("watches the topology changes", func(t *testing.T) {
+		watchCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		watch, err := admin.WatchTopology(watchCtx, &v1.WatchTopologyRequest{})
+		assert.Nil(t, err)
+
+		created, err := watch.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, root.Id, created.GetSegmentCreated().Segment.Id)
+
+		split, err := watch.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, root.Id, split.GetSegmentSplit().SegmentId)
+		assert.Greater(t, split.Position, created.Position)
+
+		replaced, err := watch.Recv()
+		assert.Nil(t, err)
+		assert.NotNil(t, replaced.GetSegmentReplaced())
+
+		// Changes are sent as they happen.
+		segment, err := admin.CreateSegment(ctx, &v1.CreateSegmentRequest{Prefix: "Bar/"})
+		assert.Nil(t, err)
+
+		created, err = watch.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, segment.Segment.Id, created.GetSegmentCreated().Segment.Id)
+		assert.Equal(t, "Bar/", created.GetSegmentCreated().Segment.Range.GetPrefix().Prefix)
+	})
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/auth"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_Authorization(t *testing.T) {
+	audit := &bytes.Buffer{}
+	authorizer := auth.NewAuthorizer(
+		auth.NewAPIKeyAuthenticator(map[string]string{
+			"orders-key":  "orders",
+			"readers-key": "readers",
+		}),
+		auth.ACL{
+			{Principal: "orders", Prefix: "Orders/", Permissions: []auth.Permission{auth.Read, auth.Write}},
+			{Principal: "readers", Prefix: "Orders/", Permissions: []auth.Permission{auth.Read}},
+		},
+	)
+	authorizer.SetAuditLog(audit)
+
+	client, closeFunc := testClientWithAuthorizer(authorizer)
+	defer closeFunc()
+
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+	}
+
+	appendTo := func(ctx context.Context, stream string) error {
+		_, err := client.Append(ctx, &v1.AppendRequest{
+			StreamName: stream,
+			Events:     []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+		})
+
+		return err
+	}
+
+	// readAll returns the number of items read, or the error of the read.
+	readAll := func(ctx context.Context, stream string) (int, error) {
+		reader, err := client.ReadStream(ctx, &v1.ReadStreamRequest{StreamName: stream})
+		if err != nil {
+			return 0, err
+		}
+
+		count := 0
+		for {
+			_, err := reader.Recv()
+			if err == io.EOF {
+				return count, nil
+			} else if err != nil {
+				return count, err
+			}
+
+			count++
+		}
+	}
+
+	stream := "Orders/" + uuid.NewString()
+
+	t.Run("rejects unauthenticated calls", func(t *testing.T) {
+		assert.Equal(t, codes.Unauthenticated, status.Code(appendTo(context.Background(), stream)))
+		assert.Equal(t, codes.Unauthenticated, status.Code(appendTo(withKey("unknown-key"), stream)))
+	})
+
+	t.Run("allows the granted calls", func(t *testing.T) {
+		assert.Nil(t, appendTo(withKey("orders-key"), stream))
+
+		// The event, and the end of the stream.
+		count, err := readAll(withKey("readers-key"), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("denies and audits the calls without the permission", func(t *testing.T) {
+		audit.Reset()
+		assert.Equal(t, codes.PermissionDenied, status.Code(appendTo(withKey("readers-key"), stream)))
+		assert.Contains(t, audit.String(), `"principal":"readers"`)
+		assert.Contains(t, audit.String(), v1.Writer_Append_FullMethodName)
+
+		_, err := readAll(withKey("orders-key"), "Payments/"+uuid.NewString())
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("enforces the permissions over HTTP", func(t *testing.T) {
+		server := httptest.NewServer(authorizer.Middleware((&Server{store: testStore(), authorizer: authorizer}).HTTPHandler()))
+		defer server.Close()
+
+		get := func(path string, key string) int {
+			request, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+			assert.Nil(t, err)
+			if key != "" {
+				request.Header.Set(auth.APIKeyHeader, key)
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			assert.Nil(t, err)
+			_, _ = io.Copy(io.Discard, response.Body)
+
+			return response.StatusCode
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, get("/streams/"+stream, ""))
+		assert.Equal(t, http.StatusOK, get("/streams/"+stream, "readers-key"))
+		assert.Equal(t, http.StatusOK, get("/query?prefix=Orders/", "readers-key"))
+		assert.Equal(t, http.StatusForbidden, get("/query?prefix=", "readers-key"))
+		assert.Equal(t, http.StatusForbidden, get("/streams/Payments/123", "orders-key"))
+	})
+}
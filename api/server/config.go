@@ -0,0 +1,203 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/sroze/fossil/api/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config configures the APIs' listeners and transports.
+type Config struct {
+	// ListenAddress is the address to bind to. Defaults to `127.0.0.1`.
+	ListenAddress string
+	Port          int
+
+	// TLS is served when not nil.
+	TLS *TLSConfig
+
+	// MaxMessageSize is the maximum size, in bytes, of the received and sent messages. Defaults
+	// to gRPC's 4MB.
+	MaxMessageSize int
+
+	Keepalive KeepaliveConfig
+
+	// Authorizer authenticates and authorizes the callers, when not nil. The health checks are
+	// not authenticated.
+	Authorizer *auth.Authorizer
+
+	// Reflection registers the gRPC reflection service, which describes the APIs to their
+	// callers. It is authenticated like the other services.
+	Reflection bool
+}
+
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is the CA verifying the client certificates, when presented.
+	ClientCAFile string
+
+	// RequireClientCert rejects the clients without a certificate verified by the client CA.
+	RequireClientCert bool
+}
+
+// KeepaliveConfig configures gRPC's keepalive. Zero values keep gRPC's defaults.
+type KeepaliveConfig struct {
+	// Time after which an idle connection is pinged.
+	Time time.Duration
+
+	// Timeout after which a pinged connection is closed.
+	Timeout time.Duration
+
+	// MinTime is the minimum time clients must wait between pings.
+	MinTime time.Duration
+
+	// PermitWithoutStream allows the clients' pings when there are no active streams.
+	PermitWithoutStream bool
+}
+
+const defaultMaxMessageSize = 4 * 1024 * 1024
+
+func (c Config) listen() (net.Listener, *net.TCPAddr, error) {
+	address := c.ListenAddress
+	if address == "" {
+		address = "127.0.0.1"
+	}
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(c.Port)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return lis, lis.Addr().(*net.TCPAddr), nil
+}
+
+func (c Config) maxMessageSize() int {
+	if c.MaxMessageSize > 0 {
+		return c.MaxMessageSize
+	}
+
+	return defaultMaxMessageSize
+}
+
+func (c Config) grpcOptions() ([]grpc.ServerOption, error) {
+	options := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(c.maxMessageSize()),
+		grpc.MaxSendMsgSize(c.maxMessageSize()),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    c.Keepalive.Time,
+			Timeout: c.Keepalive.Timeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.Keepalive.MinTime,
+			PermitWithoutStream: c.Keepalive.PermitWithoutStream,
+		}),
+		grpc.ChainUnaryInterceptor(tracingUnaryInterceptor()),
+		grpc.ChainStreamInterceptor(tracingStreamInterceptor()),
+	}
+
+	if c.TLS != nil {
+		tlsConfig, err := c.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
+		}
+
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	if c.Authorizer != nil {
+		options = append(options,
+			grpc.ChainUnaryInterceptor(authUnaryInterceptor(c.Authorizer)),
+			grpc.ChainStreamInterceptor(authStreamInterceptor(c.Authorizer)),
+		)
+	}
+
+	return options, nil
+}
+
+// unauthenticatedServices are called without credentials by the infrastructure, such as the
+// orchestrators checking the health of the servers.
+var unauthenticatedServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+}
+
+func authUnaryInterceptor(authorizer *auth.Authorizer) grpc.UnaryServerInterceptor {
+	interceptor := authorizer.UnaryServerInterceptor()
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isAuthenticated(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		return interceptor(ctx, req, info, handler)
+	}
+}
+
+func authStreamInterceptor(authorizer *auth.Authorizer) grpc.StreamServerInterceptor {
+	interceptor := authorizer.StreamServerInterceptor()
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !isAuthenticated(info.FullMethod) {
+			return handler(srv, ss)
+		}
+
+		return interceptor(srv, ss, info, handler)
+	}
+}
+
+func isAuthenticated(fullMethod string) bool {
+	for _, service := range unauthenticatedServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *TLSConfig) tlsConfig() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load TLS certificate: %w", err)
+	}
+
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := os.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client CA: %w", err)
+		}
+
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in client CA %s", c.ClientCAFile)
+		}
+
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	if c.RequireClientCert {
+		if config.ClientCAs == nil {
+			return nil, fmt.Errorf("a client CA is required to require client certificates")
+		}
+
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
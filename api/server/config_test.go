@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/auth"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificates generates a CA, and the certificates it signs.
+type testCertificates struct {
+	t      *testing.T
+	dir    string
+	ca     *x509.Certificate
+	caKey  *ecdsa.PrivateKey
+	caFile string
+}
+
+func newTestCertificates(t *testing.T) *testCertificates {
+	c := &testCertificates{t: t, dir: t.TempDir()}
+	c.ca, c.caKey, c.caFile, _ = c.generate("test-ca", nil, true)
+
+	return c
+}
+
+// generate writes the certificate and its key, returning their paths.
+func (c *testCertificates) generate(commonName string, ips []net.IP, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(c.t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           ips,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	parent, parentKey := template, key
+	if c.ca != nil {
+		parent, parentKey = c.ca, c.caKey
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(c.t, err)
+	certificate, err := x509.ParseCertificate(der)
+	assert.Nil(c.t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(c.t, err)
+
+	certFile := filepath.Join(c.dir, commonName+".crt")
+	keyFile := filepath.Join(c.dir, commonName+".key")
+	assert.Nil(c.t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	assert.Nil(c.t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+
+	return certificate, key, certFile, keyFile
+}
+
+func (c *testCertificates) clientTLS(commonName string) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AddCert(c.ca)
+	config := &tls.Config{RootCAs: roots}
+
+	if commonName != "" {
+		_, _, certFile, keyFile := c.generate(commonName, nil, false)
+		certificate, err := tls.LoadX509KeyPair(certFile, keyFile)
+		assert.Nil(c.t, err)
+		config.Certificates = []tls.Certificate{certificate}
+	}
+
+	return config
+}
+
+func Test_Config(t *testing.T) {
+	s := testStore()
+
+	appendTo := func(client v1.WriterClient, payload []byte) error {
+		_, err := client.Append(context.Background(), &v1.AppendRequest{
+			StreamName: "Foo/" + uuid.NewString(),
+			Events:     []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType", Payload: payload}},
+		})
+
+		return err
+	}
+
+	dial := func(addr *net.TCPAddr, transportCredentials credentials.TransportCredentials) v1.WriterClient {
+		conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(transportCredentials))
+		assert.Nil(t, err)
+		t.Cleanup(func() { _ = conn.Close() })
+
+		return v1.NewWriterClient(conn)
+	}
+
+	t.Run("serves TLS and authenticates client certificates", func(t *testing.T) {
+		certificates := newTestCertificates(t)
+		_, _, certFile, keyFile := certificates.generate("server", []net.IP{net.ParseIP("127.0.0.1")}, false)
+
+		err, server, addr := NewServer(s, Config{
+			TLS: &TLSConfig{
+				CertFile:          certFile,
+				KeyFile:           keyFile,
+				ClientCAFile:      certificates.caFile,
+				RequireClientCert: true,
+			},
+			Authorizer: auth.NewAuthorizer(auth.NewCertificateAuthenticator(), auth.ACL{
+				{Principal: "orders", Prefix: "Foo/", Permissions: []auth.Permission{auth.Write}},
+			}),
+		})
+		assert.Nil(t, err)
+		defer server.Stop()
+
+		assert.Nil(t, appendTo(dial(addr, credentials.NewTLS(certificates.clientTLS("orders"))), nil))
+		assert.Equal(t, codes.PermissionDenied, status.Code(appendTo(dial(addr, credentials.NewTLS(certificates.clientTLS("payments"))), nil)))
+		assert.Equal(t, codes.Unavailable, status.Code(appendTo(dial(addr, credentials.NewTLS(certificates.clientTLS(""))), nil)))
+		assert.Equal(t, codes.Unavailable, status.Code(appendTo(dial(addr, insecure.NewCredentials()), nil)))
+	})
+
+	t.Run("rejects messages larger than the maximum size", func(t *testing.T) {
+		err, server, addr := NewServer(s, Config{MaxMessageSize: 1024})
+		assert.Nil(t, err)
+		defer server.Stop()
+
+		client := dial(addr, insecure.NewCredentials())
+		assert.Nil(t, appendTo(client, make([]byte, 100)))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(appendTo(client, make([]byte, 2048))))
+	})
+
+	t.Run("serves the metrics without credentials", func(t *testing.T) {
+		err, httpServer, httpAddr := NewHTTPServer(s, Config{
+			Authorizer: auth.NewAuthorizer(auth.NewAPIKeyAuthenticator(map[string]string{"secret": "orders"}), auth.ACL{}),
+		})
+		assert.Nil(t, err)
+		defer httpServer.Close()
+
+		err, grpcServer, grpcAddr := NewServer(s, Config{})
+		assert.Nil(t, err)
+		defer grpcServer.Stop()
+		assert.Nil(t, appendTo(dial(grpcAddr, insecure.NewCredentials()), nil))
+
+		response, err := http.Get("http://" + httpAddr.String() + "/metrics")
+		assert.Nil(t, err)
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		assert.Contains(t, string(body), "fossil_append_duration_seconds")
+		assert.Contains(t, string(body), "fossil_topology_segments")
+
+		response, err = http.Get("http://" + httpAddr.String() + "/streams/Foo/" + uuid.NewString())
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	t.Run("shutting down ends the subscriptions", func(t *testing.T) {
+		err, grpcServer, _ := NewServer(s, Config{})
+		assert.Nil(t, err)
+		err, httpServer, httpAddr := NewHTTPServer(s, Config{})
+		assert.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodGet, "http://"+httpAddr.String()+"/streams/Foo/"+uuid.NewString(), nil)
+		assert.Nil(t, err)
+		request.Header.Set("Accept", EventStreamContentType)
+		response, err := http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		assert.Nil(t, Shutdown(ctx, grpcServer, httpServer))
+
+		// The subscription's response has ended.
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			assert.True(t, strings.HasPrefix(scanner.Text(), ":") || scanner.Text() == "")
+		}
+	})
+}
@@ -1,34 +1,78 @@
 package server
 
 import (
-	"fmt"
+	"context"
+	"github.com/sroze/fossil/api/auth"
 	v1 "github.com/sroze/fossil/api/v1"
 	"github.com/sroze/fossil/store"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 	"log"
 	"net"
+	"sync"
+	"sync/atomic"
 )
 
 type Server struct {
 	store *store.Store
 
+	// authorizer checks the permissions of the callers. Everything is allowed without it.
+	authorizer *auth.Authorizer
+
+	// maxMessageSize is the maximum size, in bytes, of the HTTP request bodies.
+	maxMessageSize int
+
+	// sseSubscribers is the number of concurrent Server-Sent Events subscribers.
+	sseSubscribers atomic.Int64
+
+	// closing is closed when the server shuts down, to end the subscriptions.
+	closing     chan struct{}
+	closingOnce sync.Once
+
 	v1.UnimplementedWriterServer
+	v1.UnimplementedAdminServer
 }
 
-func NewServer(store *store.Store, listenPort int) (error, *grpc.Server, *net.TCPAddr) {
-	lis, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", listenPort))
+func newServer(store *store.Store, config Config) *Server {
+	return &Server{
+		store:          store,
+		authorizer:     config.Authorizer,
+		maxMessageSize: config.maxMessageSize(),
+		closing:        make(chan struct{}),
+	}
+}
+
+// NewServer serves the gRPC API.
+func NewServer(store *store.Store, config Config) (error, *grpc.Server, *net.TCPAddr) {
+	options, err := config.grpcOptions()
 	if err != nil {
 		return err, nil, nil
 	}
 
-	addr := lis.Addr().(*net.TCPAddr)
-	s := grpc.NewServer()
-	v1.RegisterWriterServer(s, &Server{
-		store: store,
-	})
+	lis, addr, err := config.listen()
+	if err != nil {
+		return err, nil, nil
+	}
+
+	s := grpc.NewServer(options...)
+	server := newServer(store, config)
+	v1.RegisterWriterServer(s, server)
+	v1.RegisterAdminServer(s, server)
+	if config.Reflection {
+		reflection.Register(s)
+	}
+
+	// The readiness is checked for as long as the server is serving.
+	ctx, cancel := context.WithCancel(context.Background())
+	checker := newHealthChecker(store)
+	healthpb.RegisterHealthServer(s, checker.server)
+	go checker.run(ctx)
 
 	// Start the GRPC API in the background.
 	go func() {
+		defer cancel()
+
 		log.Printf("server listening at %v", addr)
 		if err := s.Serve(lis); err != nil {
 			log.Fatalf("failed to serve: %v", err)
@@ -37,3 +81,19 @@ func NewServer(store *store.Store, listenPort int) (error, *grpc.Server, *net.TC
 
 	return nil, s, addr
 }
+
+// closeSubscriptions ends the subscriptions, which would otherwise never finish.
+func (s *Server) closeSubscriptions() {
+	s.closingOnce.Do(func() {
+		close(s.closing)
+	})
+}
+
+// authorize checks that the caller has the permission on the streams starting with the prefix.
+func (s *Server) authorize(ctx context.Context, method string, permission auth.Permission, prefix string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	return s.authorizer.Authorize(ctx, method, permission, prefix)
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"github.com/apple/foundationdb/bindings/go/src/fdb"
 	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/auth"
 	v1 "github.com/sroze/fossil/api/v1"
 	"github.com/sroze/fossil/kv/foundationdb"
 	"github.com/sroze/fossil/store"
@@ -12,24 +13,51 @@ import (
 	"log"
 )
 
-func testClient() (v1.WriterClient, func() error) {
+func testStore() *store.Store {
+	return testNodes(1)[0]
+}
+
+// testNodes returns stores sharing the same KV store, as the nodes of a cluster would.
+func testNodes(count int) []*store.Store {
 	fdb.MustAPIVersion(720)
 	kv := foundationdb.NewStore(fdb.MustOpenDatabase("../../fdb.cluster"))
-	s := store.NewStore(kv, uuid.New())
-	err := s.Start()
+	id := uuid.New()
+
+	nodes := make([]*store.Store, count)
+	for i := range nodes {
+		nodes[i] = store.NewStore(kv, id)
+	}
+
+	err := nodes[0].Start()
 	if err != nil {
 		log.Fatalf("fail to start store: %v", err)
 	}
 
 	// Create a segment that covers everything.
-	_, err = s.GetTopologyManager().Create(segments.NewSegment(
+	_, err = nodes[0].GetTopologyManager().Create(segments.NewSegment(
 		segments.NewPrefixRange(""),
 	))
 	if err != nil {
 		log.Fatalf("fail to create segment: %v", err)
 	}
 
-	err, server, a := NewServer(s, 0)
+	// The other nodes are started once the segment exists, so that they know about it.
+	for _, node := range nodes[1:] {
+		err = node.Start()
+		if err != nil {
+			log.Fatalf("fail to start store: %v", err)
+		}
+	}
+
+	return nodes
+}
+
+func testClient() (v1.WriterClient, func() error) {
+	return testClientWithAuthorizer(nil)
+}
+
+func testClientWithAuthorizer(authorizer *auth.Authorizer) (v1.WriterClient, func() error) {
+	err, server, a := NewServer(testStore(), Config{Authorizer: authorizer})
 
 	// Create the gRPC client.
 	conn, err := grpc.Dial(
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/store"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"log"
+	"time"
+)
+
+// HealthCheckInterval is how often the store's readiness is checked, to update the health
+// service.
+var HealthCheckInterval = 5 * time.Second
+
+// healthCheckedServices are the services whose status is reported by the health service, in
+// addition to the whole server's (the empty service name).
+var healthCheckedServices = []string{
+	"",
+	v1.Writer_ServiceDesc.ServiceName,
+	v1.Admin_ServiceDesc.ServiceName,
+}
+
+// healthChecker reports the store's readiness through the standard `grpc.health.v1` service:
+// the services are SERVING once the topology is loaded and has a segment to write into, and
+// as long as the topology and the KV backend are healthy.
+type healthChecker struct {
+	store  *store.Store
+	server *health.Server
+
+	// ready is the last reported readiness, to only log its changes.
+	ready bool
+}
+
+func newHealthChecker(store *store.Store) *healthChecker {
+	server := health.NewServer()
+	for _, service := range healthCheckedServices {
+		server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+
+	return &healthChecker{
+		store:  store,
+		server: server,
+	}
+}
+
+// run checks the readiness until the context is done, after which everything is reported as
+// NOT_SERVING.
+func (c *healthChecker) run(ctx context.Context) {
+	defer c.server.Shutdown()
+
+	ticker := time.NewTicker(HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		c.check(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *healthChecker) check(ctx context.Context) {
+	err := c.store.Ready(ctx)
+	if ctx.Err() != nil {
+		return
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+		if c.ready {
+			log.Printf("server is not ready: %s", err)
+		}
+	}
+
+	c.ready = err == nil
+	for _, service := range healthCheckedServices {
+		c.server.SetServingStatus(service, status)
+	}
+}
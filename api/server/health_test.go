@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"github.com/sroze/fossil/api/auth"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+func Test_Health(t *testing.T) {
+	err, server, addr := NewServer(testStore(), Config{Reflection: true})
+	assert.Nil(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("reports the services as serving once ready", func(t *testing.T) {
+		client := healthpb.NewHealthClient(conn)
+		for _, service := range []string{"", v1.Writer_ServiceDesc.ServiceName, v1.Admin_ServiceDesc.ServiceName} {
+			watch, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: service})
+			assert.Nil(t, err)
+
+			for {
+				response, err := watch.Recv()
+				if !assert.Nil(t, err) || response.Status == healthpb.HealthCheckResponse_SERVING {
+					break
+				}
+			}
+		}
+	})
+
+	t.Run("lists the services through reflection", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		assert.Nil(t, err)
+		assert.Nil(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+
+		response, err := stream.Recv()
+		assert.Nil(t, err)
+
+		services := []string{}
+		for _, service := range response.GetListServicesResponse().Service {
+			services = append(services, service.Name)
+		}
+		assert.Subset(t, services, []string{
+			v1.Writer_ServiceDesc.ServiceName,
+			v1.Admin_ServiceDesc.ServiceName,
+			healthpb.Health_ServiceDesc.ServiceName,
+		})
+	})
+}
+
+func Test_HealthWithAuthorization(t *testing.T) {
+	authorizer := auth.NewAuthorizer(auth.NewAPIKeyAuthenticator(map[string]string{}), auth.ACL{})
+	err, server, addr := NewServer(testStore(), Config{Authorizer: authorizer})
+	assert.Nil(t, err)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("checks the health without credentials", func(t *testing.T) {
+		_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		assert.Nil(t, err)
+	})
+
+	t.Run("authenticates the other services", func(t *testing.T) {
+		_, err := v1.NewWriterClient(conn).Append(ctx, &v1.AppendRequest{StreamName: "Foo/bar"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("does not register the reflection by default", func(t *testing.T) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		assert.Nil(t, err)
+		assert.Nil(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}))
+
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err))
+	})
+}
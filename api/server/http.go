@@ -0,0 +1,446 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sroze/fossil/api/auth"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	ExpectedPositionHeader = "Expected-Position"
+	NDJSONContentType      = "application/x-ndjson"
+)
+
+// maxQueryLimit caps the number of events returned by a `/query` request, as they are
+// collected in memory before being written unless they are streamed as newline-delimited JSON.
+const maxQueryLimit = 1000
+
+var jsonMarshaller = protojson.MarshalOptions{UseProtoNames: true}
+
+// NewHTTPServer serves the HTTP/JSON gateway, and the Prometheus metrics on `/metrics`. Requests
+// and responses are the JSON mapping of the gRPC messages.
+func NewHTTPServer(store *store.Store, config Config) (error, *http.Server, *net.TCPAddr) {
+	var tlsConfig *tls.Config
+	if config.TLS != nil {
+		var err error
+		tlsConfig, err = config.TLS.tlsConfig()
+		if err != nil {
+			return err, nil, nil
+		}
+	}
+
+	lis, addr, err := config.listen()
+	if err != nil {
+		return err, nil, nil
+	}
+
+	server := newServer(store, config)
+	handler := server.HTTPHandler()
+	if config.Authorizer != nil {
+		handler = config.Authorizer.Middleware(handler)
+	}
+
+	// The Prometheus metrics are scraped without credentials.
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/", handler)
+
+	s := &http.Server{
+		Handler:   mux,
+		TLSConfig: tlsConfig,
+	}
+
+	// Shutting down waits for the connections to be idle: subscriptions have to end.
+	s.RegisterOnShutdown(server.closeSubscriptions)
+
+	// Start the HTTP API in the background.
+	go func() {
+		log.Printf("http server listening at %v", addr)
+
+		var err error
+		if tlsConfig != nil {
+			err = s.ServeTLS(lis, "", "")
+		} else {
+			err = s.Serve(lis)
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	return nil, s, addr
+}
+
+// HTTPHandler routes the requests of the HTTP/JSON gateway:
+//   - `POST /streams/{name}` appends events, optionally expecting the stream's position with
+//     the `Expected-Position` header.
+//   - `GET /streams/{name}?from=&limit=&direction=` reads a stream. `from` is a position or `end`,
+//     and `direction` is `forward` or `backward`.
+//   - `GET /query?prefix=&cursor=&limit=` reads the events of all the streams matching the prefix.
+//     The cursors are base64-encoded, so they need to be URL-encoded in the query string. At most
+//     `limit` events are returned, and never more than 1000: the last event's cursor resumes
+//     the query.
+//
+// Reads return a JSON document, or newline-delimited JSON when requested with the `Accept` header.
+// When requested with `text/event-stream`, they follow the stream or prefix as Server-Sent Events.
+func (s *Server) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/streams/", func(w http.ResponseWriter, r *http.Request) {
+		stream := strings.TrimPrefix(r.URL.Path, "/streams/")
+		if stream == "" {
+			writeHTTPError(w, status.Error(codes.NotFound, "stream name is missing"))
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			s.httpAppend(w, r, stream)
+		case http.MethodGet:
+			if wantsEventStream(r) {
+				s.sseStream(w, r, stream)
+			} else {
+				s.httpReadStream(w, r, stream)
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			writeHTTPError(w, errMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/query", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeHTTPError(w, errMethodNotAllowed)
+			return
+		}
+
+		if wantsEventStream(r) {
+			s.sseQuery(w, r)
+		} else {
+			s.httpQuery(w, r)
+		}
+	})
+
+	return mux
+}
+
+var errMethodNotAllowed = errors.New("method not allowed")
+
+func (s *Server) httpAppend(w http.ResponseWriter, r *http.Request, stream string) {
+	if s.maxMessageSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(s.maxMessageSize))
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		if tooLarge := (&http.MaxBytesError{}); errors.As(err, &tooLarge) {
+			writeHTTPError(w, status.Errorf(codes.ResourceExhausted, "body is larger than %d bytes", tooLarge.Limit))
+			return
+		}
+
+		writeHTTPError(w, status.Errorf(codes.InvalidArgument, "cannot read body: %s", err))
+		return
+	}
+
+	request := &v1.AppendRequest{}
+	err = protojson.Unmarshal(body, request)
+	if err != nil {
+		writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid body: %s", err))
+		return
+	}
+
+	request.StreamName = stream
+	if header := r.Header.Get(ExpectedPositionHeader); header != "" {
+		expectedPosition, err := strconv.ParseInt(header, 10, 64)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid %s header: %s", ExpectedPositionHeader, err))
+			return
+		}
+
+		request.ExpectedPosition = &expectedPosition
+	}
+
+	reply, err := s.Append(r.Context(), request)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, reply)
+}
+
+func (s *Server) httpReadStream(w http.ResponseWriter, r *http.Request, stream string) {
+	query := r.URL.Query()
+	request := &v1.ReadStreamRequest{
+		StreamName: stream,
+	}
+
+	if from := query.Get("from"); from == "end" {
+		request.FromEnd = true
+	} else if from != "" {
+		position, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid `from`: %s", err))
+			return
+		}
+
+		request.StartingPosition = position
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.ParseUint(limit, 10, 32)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid `limit`: %s", err))
+			return
+		}
+
+		request.Limit = uint32(l)
+	}
+
+	switch query.Get("direction") {
+	case "", "forward":
+		request.Direction = v1.ReadDirection_FORWARD
+	case "backward":
+		request.Direction = v1.ReadDirection_BACKWARD
+	default:
+		writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid `direction`: expected `forward` or `backward`"))
+		return
+	}
+
+	items := newHTTPItemWriter(w, r)
+	err := s.ReadStream(request, &httpReadStreamServer{ctx: r.Context(), items: items})
+	items.Close(err)
+}
+
+func (s *Server) httpQuery(w http.ResponseWriter, r *http.Request) {
+	err := s.authorize(r.Context(), "GET /query", auth.Read, r.URL.Query().Get("prefix"))
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	limit := uint64(maxQueryLimit)
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.ParseUint(l, 10, 32)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid `limit`: %s", err))
+			return
+		}
+
+		if limit == 0 || limit > maxQueryLimit {
+			limit = maxQueryLimit
+		}
+	}
+
+	// The query is cancelled once the limit is reached.
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	ch := make(chan eskit.SourceItem)
+	go store.NewEventSource(s.store).Query(ctx, r.URL.Query().Get("prefix"), r.URL.Query().Get("cursor"), ch)
+
+	items := newHTTPItemWriter(w, r)
+	count := uint64(0)
+	for item := range ch {
+		if err != nil || count >= limit {
+			cancel()
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+			continue
+		}
+
+		err = items.Write(&v1.QueryReplyItem{
+			StreamName:     item.EventInStream.Stream,
+			StreamPosition: item.EventInStream.Position,
+			EventId:        item.EventInStream.Event.EventId,
+			EventType:      item.EventInStream.Event.EventType,
+			Payload:        item.EventInStream.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(item.EventInStream.Event.Metadata),
+			Cursor:         item.Cursor,
+		})
+		count++
+	}
+
+	items.Close(err)
+}
+
+// httpReadStreamServer sends the items of `ReadStream` to the HTTP response.
+type httpReadStreamServer struct {
+	grpc.ServerStream
+
+	ctx   context.Context
+	items *httpItemWriter
+}
+
+func (s *httpReadStreamServer) Context() context.Context {
+	return s.ctx
+}
+
+func (s *httpReadStreamServer) Send(item *v1.ReadStreamReplyItem) error {
+	return s.items.Write(item)
+}
+
+// httpItemWriter writes the items of a read to the response, either as they come (with
+// newline-delimited JSON) or in a single JSON array once the read is done, so that its
+// errors can be reported with the response's status code.
+type httpItemWriter struct {
+	w       http.ResponseWriter
+	ndjson  bool
+	started bool
+	items   []json.RawMessage
+}
+
+func newHTTPItemWriter(w http.ResponseWriter, r *http.Request) *httpItemWriter {
+	return &httpItemWriter{
+		w:      w,
+		ndjson: strings.Contains(r.Header.Get("Accept"), NDJSONContentType),
+	}
+}
+
+func (iw *httpItemWriter) Write(item proto.Message) error {
+	b, err := jsonMarshaller.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	if !iw.ndjson {
+		iw.items = append(iw.items, b)
+		return nil
+	}
+
+	if !iw.started {
+		iw.w.Header().Set("Content-Type", NDJSONContentType)
+		iw.w.WriteHeader(http.StatusOK)
+		iw.started = true
+	}
+
+	_, err = iw.w.Write(append(b, '\n'))
+	if f, ok := iw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+
+	return err
+}
+
+// Close ends the response with the error of the read, if any. Once newline-delimited items
+// were sent, the error is sent as the last line.
+func (iw *httpItemWriter) Close(err error) {
+	if err != nil {
+		if iw.started {
+			b, _ := json.Marshal(httpError{Error: err.Error()})
+			_, _ = iw.w.Write(append(b, '\n'))
+		} else {
+			writeHTTPError(iw.w, err)
+		}
+
+		return
+	}
+
+	if iw.ndjson {
+		if !iw.started {
+			iw.w.Header().Set("Content-Type", NDJSONContentType)
+			iw.w.WriteHeader(http.StatusOK)
+		}
+
+		return
+	}
+
+	items := iw.items
+	if items == nil {
+		items = []json.RawMessage{}
+	}
+
+	iw.w.Header().Set("Content-Type", "application/json")
+	iw.w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(iw.w).Encode(items)
+}
+
+type httpError struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, message proto.Message) {
+	b, err := jsonMarshaller.Marshal(message)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(b)
+}
+
+func writeHTTPError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatusFor(err))
+	_ = json.NewEncoder(w).Encode(httpError{Error: errorMessage(err)})
+}
+
+// httpStatusFor maps the errors of the store, and the gRPC codes of the API's errors, to HTTP
+// status codes.
+func httpStatusFor(err error) int {
+	if errors.As(err, &simplestore.StreamConditionFailed{}) {
+		return http.StatusConflict
+	} else if errors.Is(err, simplestore.StoreIsClosedErr{}) {
+		return http.StatusServiceUnavailable
+	} else if errors.Is(err, errMethodNotAllowed) {
+		return http.StatusMethodNotAllowed
+	}
+
+	s, ok := status.FromError(err)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
+	switch s.Code() {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition, codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusRequestEntityTooLarge
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Canceled:
+		return 499
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func errorMessage(err error) string {
+	if s, ok := status.FromError(err); ok {
+		return s.Message()
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_HTTP(t *testing.T) {
+	server := httptest.NewServer((&Server{store: testStore()}).HTTPHandler())
+	defer server.Close()
+
+	appendEvents := func(stream string, expectedPosition string, eventIds ...string) *http.Response {
+		events := make([]*v1.EventToAppend, len(eventIds))
+		for i, eventId := range eventIds {
+			events[i] = &v1.EventToAppend{EventId: eventId, EventType: "AnEventType", Payload: []byte(`{"foo": 123}`)}
+		}
+
+		body, err := protojson.Marshal(&v1.AppendRequest{Events: events})
+		assert.Nil(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, server.URL+"/streams/"+stream, bytes.NewReader(body))
+		assert.Nil(t, err)
+		if expectedPosition != "" {
+			request.Header.Set(ExpectedPositionHeader, expectedPosition)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+
+		return response
+	}
+
+	get := func(path string, accept string) *http.Response {
+		request, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		assert.Nil(t, err)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+
+		return response
+	}
+
+	readItems := func(response *http.Response) []*v1.ReadStreamReplyItem {
+		var rawItems []json.RawMessage
+		assert.Nil(t, json.NewDecoder(response.Body).Decode(&rawItems))
+
+		items := make([]*v1.ReadStreamReplyItem, len(rawItems))
+		for i, rawItem := range rawItems {
+			items[i] = &v1.ReadStreamReplyItem{}
+			assert.Nil(t, protojson.Unmarshal(rawItem, items[i]))
+		}
+
+		return items
+	}
+
+	stream := "Foo/" + uuid.NewString()
+	eventIds := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+	response := appendEvents(stream, "", eventIds...)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	t.Run("appends events and returns the stream position", func(t *testing.T) {
+		response := appendEvents(stream, "2", uuid.NewString())
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		reply := &v1.AppendReply{}
+		body, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		assert.Nil(t, protojson.Unmarshal(body, reply))
+		assert.Equal(t, int64(3), reply.StreamPosition)
+	})
+
+	t.Run("returns a conflict when the stream is not at the expected position", func(t *testing.T) {
+		response := appendEvents(stream, "0", uuid.NewString())
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		response, err := http.Post(server.URL+"/streams/"+stream, "application/json", strings.NewReader("not json"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		response = appendEvents(stream, "not-a-position", uuid.NewString())
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		response = get("/streams/"+stream+"?direction=sideways", "")
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		request, err := http.NewRequest(http.MethodDelete, server.URL+"/streams/"+stream, nil)
+		assert.Nil(t, err)
+		response, err = http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+	})
+
+	t.Run("reads a stream as JSON, ending with its head position", func(t *testing.T) {
+		response := get("/streams/"+stream+"?limit=2", "")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+		items := readItems(response)
+		assert.Equal(t, 3, len(items))
+		assert.Equal(t, eventIds[0], items[0].EventId)
+		assert.Equal(t, []byte(`{"foo": 123}`), items[0].Payload)
+		assert.Equal(t, eventIds[1], items[1].EventId)
+		assert.Equal(t, int64(3), items[2].EndOfStream.StreamPosition)
+	})
+
+	t.Run("reads a stream backwards from the end", func(t *testing.T) {
+		items := readItems(get("/streams/"+stream+"?from=end&direction=backward&limit=2", ""))
+		assert.Equal(t, 3, len(items))
+		assert.Equal(t, int64(3), items[0].StreamPosition)
+		assert.Equal(t, eventIds[2], items[1].EventId)
+	})
+
+	t.Run("reads a stream as newline-delimited JSON", func(t *testing.T) {
+		response := get("/streams/"+stream, NDJSONContentType)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, NDJSONContentType, response.Header.Get("Content-Type"))
+
+		var items []*v1.ReadStreamReplyItem
+		scanner := bufio.NewScanner(response.Body)
+		for scanner.Scan() {
+			item := &v1.ReadStreamReplyItem{}
+			assert.Nil(t, protojson.Unmarshal(scanner.Bytes(), item))
+			items = append(items, item)
+		}
+
+		assert.Equal(t, 5, len(items))
+		assert.Equal(t, eventIds[0], items[0].EventId)
+		assert.Equal(t, int64(3), items[4].EndOfStream.StreamPosition)
+	})
+
+	t.Run("queries the streams matching a prefix, from a cursor", func(t *testing.T) {
+		prefix := "Bar/" + uuid.NewString() + "/"
+		for i := 0; i < 3; i++ {
+			response := appendEvents(fmt.Sprintf("%s%d", prefix, i), "", uuid.NewString())
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+
+		query := func(cursor string, limit string) []*v1.QueryReplyItem {
+			response := get("/query?prefix="+url.QueryEscape(prefix)+"&cursor="+url.QueryEscape(cursor)+"&limit="+limit, "")
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+
+			var rawItems []json.RawMessage
+			assert.Nil(t, json.NewDecoder(response.Body).Decode(&rawItems))
+
+			items := make([]*v1.QueryReplyItem, len(rawItems))
+			for i, rawItem := range rawItems {
+				items[i] = &v1.QueryReplyItem{}
+				assert.Nil(t, protojson.Unmarshal(rawItem, items[i]))
+			}
+
+			return items
+		}
+
+		items := query("", "")
+		assert.Equal(t, 3, len(items))
+		assert.True(t, strings.HasPrefix(items[0].StreamName, prefix))
+
+		resumedItems := query(items[0].Cursor, "")
+		assert.Equal(t, 2, len(resumedItems))
+		assert.Equal(t, items[1].EventId, resumedItems[0].EventId)
+
+		t.Run("with a limit", func(t *testing.T) {
+			limitedItems := query("", "2")
+			assert.Equal(t, items[:2], limitedItems)
+
+			resumedItems := query(limitedItems[1].Cursor, "2")
+			assert.Equal(t, items[2:], resumedItems)
+		})
+
+		t.Run("rejects invalid limits", func(t *testing.T) {
+			response := get("/query?prefix="+url.QueryEscape(prefix)+"&limit=-1", "")
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		})
+	})
+}
+
+func Test_httpStatusFor(t *testing.T) {
+	assert.Equal(t, http.StatusConflict, httpStatusFor(fmt.Errorf("write failed: %w", simplestore.StreamConditionFailed{})))
+	assert.Equal(t, http.StatusServiceUnavailable, httpStatusFor(fmt.Errorf("write failed: %w", simplestore.StoreIsClosedErr{})))
+	assert.Equal(t, http.StatusInternalServerError, httpStatusFor(io.EOF))
+}
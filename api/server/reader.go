@@ -1,41 +1,292 @@
 package server
 
 import (
+	"context"
 	"fmt"
+	"github.com/sroze/fossil/api/auth"
 	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/livetail"
 	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
 )
 
 func (s *Server) ReadStream(request *v1.ReadStreamRequest, server v1.Writer_ReadStreamServer) error {
-	ch := make(chan simplestore.ReadItem, 10)
+	err := s.authorize(server.Context(), v1.Writer_ReadStream_FullMethodName, auth.Read, request.StreamName)
+	if err != nil {
+		return err
+	}
+
+	options, reversed, err := s.readOptionsFor(server.Context(), request)
+	if err != nil {
+		return err
+	}
+
 	if request.Subscribe {
-		// FIXME: We need to implement this with LiveTail, the store itself
-		//        doesn't support "read & follow" anymore.
-		// go s.streamStore.ReadAndFollow(server.Context(), request.StreamName, request.StartingPosition, ch)
-	} else {
-		go s.store.Read(server.Context(), request.StreamName, ch, simplestore.ReadOptions{
-			StartingPosition: request.StartingPosition,
+		return s.followStream(request, options, server)
+	}
+
+	ch := make(chan simplestore.ReadItem, 10)
+	go s.store.Read(server.Context(), request.StreamName, ch, options)
+
+	send := func(event *simplestore.EventInStream) error {
+		err := server.Send(&v1.ReadStreamReplyItem{
+			StreamPosition: event.Position,
+			EventId:        event.Event.EventId,
+			EventType:      event.Event.EventType,
+			Payload:        event.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(event.Event.Metadata),
 		})
+
+		if err != nil {
+			return fmt.Errorf("error while sending stream item: %w", err)
+		}
+
+		return nil
 	}
 
+	// The events of reversed reads are sent once they all have been read.
+	var events []*simplestore.EventInStream
 	for item := range ch {
 		if item.Error != nil {
 			return fmt.Errorf("error while reading stream: %w", item.Error)
 		}
 
-		if item.EventInStream != nil {
-			err := server.Send(&v1.ReadStreamReplyItem{
-				StreamPosition: item.EventInStream.Position,
-				EventId:        item.EventInStream.Event.EventId,
-				EventType:      item.EventInStream.Event.EventType,
-				Payload:        item.EventInStream.Event.Payload,
+		if item.EventInStream == nil {
+			continue
+		}
+
+		if reversed {
+			events = append(events, item.EventInStream)
+		} else if err := send(item.EventInStream); err != nil {
+			return err
+		}
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		if err := send(events[i]); err != nil {
+			return err
+		}
+	}
+
+	head, err := s.streamHead(server.Context(), request.StreamName)
+	if err != nil {
+		return fmt.Errorf("error while reading stream head: %w", err)
+	}
+
+	err = server.Send(&v1.ReadStreamReplyItem{
+		EndOfStream: &v1.EndOfStreamSignal{
+			StreamPosition: head,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("error while sending end of stream: %w", err)
+	}
+
+	return nil
+}
+
+// followStream sends the events of the stream as they are appended, until the client goes away
+// or the server shuts down.
+func (s *Server) followStream(request *v1.ReadStreamRequest, options simplestore.ReadOptions, server v1.Writer_ReadStreamServer) error {
+	sent := 0
+	reader := livetail.NewStreamReader(s.store, request.StreamName)
+
+	return s.follow(server.Context(), reader, strconv.FormatInt(options.StartingPosition, 10), func(item simplestore.ReadItem) (bool, error) {
+		if item.EventInStream == nil {
+			return false, server.Send(&v1.ReadStreamReplyItem{
+				EndOfStream: &v1.EndOfStreamSignal{
+					StreamPosition: item.EndOfStreamSignal.StreamPosition,
+				},
 			})
+		}
+
+		err := server.Send(&v1.ReadStreamReplyItem{
+			StreamPosition: item.EventInStream.Position,
+			EventId:        item.EventInStream.Event.EventId,
+			EventType:      item.EventInStream.Event.EventType,
+			Payload:        item.EventInStream.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(item.EventInStream.Event.Metadata),
+		})
+
+		sent++
+		return options.Limit > 0 && sent >= options.Limit, err
+	})
+}
+
+func (s *Server) Query(request *v1.QueryRequest, server v1.Writer_QueryServer) error {
+	err := s.authorize(server.Context(), v1.Writer_Query_FullMethodName, auth.Read, request.Prefix)
+	if err != nil {
+		return err
+	}
+
+	send := func(item simplestore.ReadItem) (bool, error) {
+		if item.EventInStream == nil {
+			return false, nil
+		}
+
+		return false, server.Send(&v1.QueryReplyItem{
+			StreamName:     item.EventInStream.Stream,
+			StreamPosition: item.EventInStream.Position,
+			EventId:        item.EventInStream.Event.EventId,
+			EventType:      item.EventInStream.Event.EventType,
+			Payload:        item.EventInStream.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(item.EventInStream.Event.Metadata),
+			Cursor:         item.Cursor,
+		})
+	}
+
+	if request.Subscribe {
+		return s.follow(server.Context(), store.NewPrefixReader(s.store, request.Prefix), request.Cursor, send)
+	}
+
+	ch := make(chan eskit.SourceItem)
+	go store.NewEventSource(s.store).Query(server.Context(), request.Prefix, request.Cursor, ch)
+
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = fmt.Errorf("error while querying: %w", item.Error)
+			continue
+		}
+
+		_, err = send(simplestore.ReadItem{EventInStream: item.EventInStream, Cursor: item.Cursor})
+	}
+
+	return err
+}
 
+// follow sends the items of the live tail until the client goes away, the server shuts down,
+// or `send` is done. Only the first end of stream is sent: the live tail reaches it again on
+// every poll.
+func (s *Server) follow(
+	ctx context.Context,
+	reader livetail.Reader,
+	startingPosition string,
+	send func(item simplestore.ReadItem) (bool, error),
+) error {
+	tail := livetail.NewLiveTail(reader)
+	ch := make(chan simplestore.ReadItem)
+	go tail.Start(startingPosition, ch)
+
+	// The channel is drained so that the live tail can stop.
+	defer func() {
+		tail.Stop()
+		for range ch {
+		}
+	}()
+
+	reachedEndOfStream := false
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.closing:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case item, more := <-ch:
+			if !more {
+				return nil
+			}
+
+			if item.Error != nil {
+				return fmt.Errorf("error while following: %w", item.Error)
+			}
+
+			if item.EventInStream == nil {
+				if item.EndOfStreamSignal == nil || reachedEndOfStream {
+					continue
+				}
+
+				reachedEndOfStream = true
+			}
+
+			done, err := send(item)
 			if err != nil {
-				return fmt.Errorf("error while sending stream item: %w", err)
+				return fmt.Errorf("error while sending item: %w", err)
+			} else if done {
+				return nil
 			}
 		}
 	}
+}
 
-	return nil
+// readOptionsFor returns the options to read the store with, and whether the read events are
+// to be sent in reverse order.
+func (s *Server) readOptionsFor(ctx context.Context, request *v1.ReadStreamRequest) (simplestore.ReadOptions, bool, error) {
+	if request.StartingPosition < 0 {
+		return simplestore.ReadOptions{}, false, status.Errorf(codes.InvalidArgument, "starting position must be positive, got %d", request.StartingPosition)
+	}
+
+	backwards := request.Direction == v1.ReadDirection_BACKWARD
+	if backwards && request.Subscribe {
+		return simplestore.ReadOptions{}, false, status.Error(codes.InvalidArgument, "cannot subscribe to a backwards read")
+	}
+
+	options := simplestore.ReadOptions{
+		StartingPosition: request.StartingPosition,
+		Limit:            int(request.Limit),
+		Backwards:        backwards,
+	}
+
+	if backwards {
+		if request.FromEnd {
+			options.StartingPosition = 0
+			return options, false, nil
+		}
+
+		// The store reads backwards from the head of the stream: we rather read forwards from the
+		// lowest position to be sent up to the starting position, and reverse the events.
+		lastPosition := request.StartingPosition
+		options.StartingPosition = 0
+		if request.Limit > 0 && lastPosition-int64(request.Limit)+1 > 0 {
+			options.StartingPosition = lastPosition - int64(request.Limit) + 1
+		}
+
+		options.Limit = int(lastPosition - options.StartingPosition + 1)
+		options.Backwards = false
+
+		return options, true, nil
+	}
+
+	if request.FromEnd {
+		head, err := s.streamHead(ctx, request.StreamName)
+		if err != nil {
+			return simplestore.ReadOptions{}, false, status.Errorf(codes.Unavailable, "cannot read stream head: %s", err)
+		}
+
+		options.StartingPosition = head + 1
+		if request.Limit > 0 {
+			options.StartingPosition = head - int64(request.Limit) + 1
+			if options.StartingPosition < 0 {
+				options.StartingPosition = 0
+			}
+		}
+	}
+
+	return options, false, nil
+}
+
+// streamHead returns the position of the stream's last event, or `-1` if the stream is empty.
+func (s *Server) streamHead(ctx context.Context, stream string) (int64, error) {
+	ch := make(chan simplestore.ReadItem)
+	go s.store.Read(ctx, stream, ch, simplestore.ReadOptions{
+		Backwards: true,
+		Limit:     1,
+	})
+
+	head := int64(-1)
+	var err error
+	for item := range ch {
+		if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil && head < item.EventInStream.Position {
+			head = item.EventInStream.Position
+		}
+	}
+
+	return head, err
 }
@@ -4,11 +4,13 @@ import (
 	"context"
 	"github.com/google/uuid"
 	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"io"
 	"testing"
+	"time"
 )
 
 func ReaderAsChannel(stream v1.Writer_ReadStreamClient) chan *v1.ReadStreamReplyItem {
@@ -60,7 +62,11 @@ func Test_reader(t *testing.T) {
 			assert.Equal(t, dummyEventIds[i], response.EventId)
 		}
 
-		// Expects the stream to be closed.
+		// Expects the stream to end with its head position, and to be closed.
+		response, err := stream.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(19), response.EndOfStream.StreamPosition)
+
 		_, err = stream.Recv()
 		assert.Equal(t, io.EOF, err)
 	})
@@ -82,63 +88,272 @@ func Test_reader(t *testing.T) {
 			assert.Equal(t, expectedEvents[i], response.EventId)
 		}
 
-		// Expects the stream to be closed.
+		// Expects the stream to end with its head position, and to be closed.
+		response, err := stream.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(19), response.EndOfStream.StreamPosition)
+
 		_, err = stream.Recv()
 		assert.Equal(t, io.EOF, err)
 	})
 
-	// TODO: reintroduce subscribe with `livetail`
-	//t.Run("stream all events and continue to stream from there", func(t *testing.T) {
-	//	anotherStream := "Foo/" + uuid.NewString()
-	//	dummyEventIds, err := FillStreamWithDummyEvents(c, anotherStream, 5)
-	//	assert.Nil(t, err)
-	//
-	//	// Start streaming all events.
-	//	ctx, cancel := context.WithCancel(context.Background())
-	//	defer cancel()
-	//
-	//	stream, err := c.ReadStream(ctx, &v1.ReadStreamRequest{
-	//		StreamName: anotherStream,
-	//		Subscribe:  true,
-	//	})
-	//	assert.Nil(t, err)
-	//
-	//	// Expects all the events to be streamed.
-	//	channel := ReaderAsChannel(stream)
-	//	for i := 0; i < len(dummyEventIds); i++ {
-	//		event := <-channel
-	//
-	//		assert.Equal(t, dummyEventIds[i], event.EventId)
-	//	}
-	//
-	//	// Expects reading to timeout.
-	//	select {
-	//	case <-channel:
-	//		t.Error("expected stream to be pending instead")
-	//	case <-time.After(100 * time.Millisecond):
-	//		// this is expected, yay!
-	//	}
-	//
-	//	// Send an event.
-	//	anotherEventId := uuid.New().String()
-	//	_, err = c.AppendEvent(context.Background(), &v1.AppendRequest{
-	//		StreamName: anotherStream,
-	//		EventId:    anotherEventId,
-	//		EventType:  "AnEventType",
-	//		Payload:    []byte("{\"foo\": 123}"),
-	//	})
-	//	assert.Nil(t, err)
-	//
-	//	// Expects the event to be streamed within reasonable timeframes.
-	//	select {
-	//	case event, more := <-channel:
-	//		if !more {
-	//			t.Error("expected stream to be filled instead of being closed")
-	//		} else {
-	//			assert.Equal(t, anotherEventId, event.EventId)
-	//		}
-	//	case <-time.After(1000 * time.Millisecond):
-	//		t.Error("expected stream to be filled instead of receiving timeout")
-	//	}
-	//})
+	readAll := func(request *v1.ReadStreamRequest) ([]string, *v1.EndOfStreamSignal, error) {
+		stream, err := c.ReadStream(context.Background(), request)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var eventIds []string
+		var endOfStream *v1.EndOfStreamSignal
+		for {
+			response, err := stream.Recv()
+			if err == io.EOF {
+				return eventIds, endOfStream, nil
+			} else if err != nil {
+				return nil, nil, err
+			}
+
+			if response.EndOfStream != nil {
+				endOfStream = response.EndOfStream
+			} else {
+				eventIds = append(eventIds, response.EventId)
+			}
+		}
+	}
+
+	reversed := func(eventIds []string) []string {
+		r := make([]string, len(eventIds))
+		for i, eventId := range eventIds {
+			r[len(eventIds)-1-i] = eventId
+		}
+
+		return r
+	}
+
+	t.Run("read options", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			request  *v1.ReadStreamRequest
+			expected []string
+		}{
+			{
+				name:     "with a limit",
+				request:  &v1.ReadStreamRequest{StartingPosition: 4, Limit: 3},
+				expected: dummyEventIds[4:7],
+			},
+			{
+				name:     "the last events, in order",
+				request:  &v1.ReadStreamRequest{FromEnd: true, Limit: 5},
+				expected: dummyEventIds[15:],
+			},
+			{
+				name:     "after the last event",
+				request:  &v1.ReadStreamRequest{FromEnd: true},
+				expected: nil,
+			},
+			{
+				name:     "backwards from the end",
+				request:  &v1.ReadStreamRequest{Direction: v1.ReadDirection_BACKWARD, FromEnd: true, Limit: 5},
+				expected: reversed(dummyEventIds[15:]),
+			},
+			{
+				name:     "backwards from a position",
+				request:  &v1.ReadStreamRequest{Direction: v1.ReadDirection_BACKWARD, StartingPosition: 9, Limit: 5},
+				expected: reversed(dummyEventIds[5:10]),
+			},
+			{
+				name:     "backwards from a position, down to the beginning",
+				request:  &v1.ReadStreamRequest{Direction: v1.ReadDirection_BACKWARD, StartingPosition: 2},
+				expected: reversed(dummyEventIds[:3]),
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				tc.request.StreamName = stream
+				eventIds, endOfStream, err := readAll(tc.request)
+				assert.Nil(t, err)
+				assert.Equal(t, tc.expected, eventIds)
+				assert.Equal(t, int64(19), endOfStream.StreamPosition)
+			})
+		}
+	})
+
+	t.Run("an empty stream ends with a negative head position", func(t *testing.T) {
+		eventIds, endOfStream, err := readAll(&v1.ReadStreamRequest{
+			StreamName: "Foo/" + uuid.NewString(),
+			FromEnd:    true,
+			Limit:      10,
+		})
+		assert.Nil(t, err)
+		assert.Empty(t, eventIds)
+		assert.Equal(t, int64(-1), endOfStream.StreamPosition)
+	})
+
+	t.Run("rejects a negative starting position", func(t *testing.T) {
+		_, _, err := readAll(&v1.ReadStreamRequest{
+			StreamName:       stream,
+			StartingPosition: -1,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("stream all events and continue to stream from there", func(t *testing.T) {
+		anotherStream := "Foo/" + uuid.NewString()
+		dummyEventIds, err := FillStreamWithDummyEvents(c, anotherStream, 5)
+		assert.Nil(t, err)
+
+		// Start streaming all events.
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		stream, err := c.ReadStream(ctx, &v1.ReadStreamRequest{
+			StreamName: anotherStream,
+			Subscribe:  true,
+		})
+		assert.Nil(t, err)
+
+		// Expects all the events to be streamed, followed by the end of stream.
+		channel := ReaderAsChannel(stream)
+		for i := 0; i < len(dummyEventIds); i++ {
+			event := <-channel
+
+			assert.Equal(t, dummyEventIds[i], event.EventId)
+		}
+
+		endOfStream := <-channel
+		assert.Equal(t, int64(4), endOfStream.EndOfStream.StreamPosition)
+
+		// Expects reading to timeout.
+		select {
+		case <-channel:
+			t.Error("expected stream to be pending instead")
+		case <-time.After(300 * time.Millisecond):
+			// this is expected, yay!
+		}
+
+		// Send an event.
+		anotherEventIds, err := FillStreamWithDummyEvents(c, anotherStream, 1)
+		assert.Nil(t, err)
+
+		// Expects the event to be streamed within reasonable timeframes.
+		select {
+		case event, more := <-channel:
+			if !more {
+				t.Error("expected stream to be filled instead of being closed")
+			} else {
+				assert.Equal(t, anotherEventIds[0], event.EventId)
+				assert.Equal(t, int64(5), event.StreamPosition)
+			}
+		case <-time.After(1000 * time.Millisecond):
+			t.Error("expected stream to be filled instead of receiving timeout")
+		}
+	})
+
+	t.Run("rejects subscribing to a backwards read", func(t *testing.T) {
+		_, _, err := readAll(&v1.ReadStreamRequest{
+			StreamName: stream,
+			Subscribe:  true,
+			Direction:  v1.ReadDirection_BACKWARD,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func Test_Query(t *testing.T) {
+	c, end := testClient()
+	defer end()
+
+	prefix := "Query/" + uuid.NewString() + "/"
+	first, err := FillStreamWithDummyEvents(c, prefix+"a", 2)
+	assert.Nil(t, err)
+	second, err := FillStreamWithDummyEvents(c, prefix+"b", 1)
+	assert.Nil(t, err)
+
+	queryAll := func(request *v1.QueryRequest) ([]*v1.QueryReplyItem, error) {
+		query, err := c.Query(context.Background(), request)
+		if err != nil {
+			return nil, err
+		}
+
+		var items []*v1.QueryReplyItem
+		for {
+			item, err := query.Recv()
+			if err == io.EOF {
+				return items, nil
+			} else if err != nil {
+				return items, err
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	t.Run("reads the events of the streams matching the prefix", func(t *testing.T) {
+		items, err := queryAll(&v1.QueryRequest{Prefix: prefix})
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(items))
+
+		var eventIds []string
+		for _, item := range items {
+			eventIds = append(eventIds, item.EventId)
+			assert.NotEmpty(t, item.Cursor)
+		}
+		assert.ElementsMatch(t, append(first, second...), eventIds)
+	})
+
+	t.Run("resumes from a cursor", func(t *testing.T) {
+		items, err := queryAll(&v1.QueryRequest{Prefix: prefix})
+		assert.Nil(t, err)
+
+		resumed, err := queryAll(&v1.QueryRequest{Prefix: prefix, Cursor: items[0].Cursor})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(resumed))
+		for i, item := range resumed {
+			assert.Equal(t, items[i+1].EventId, item.EventId)
+		}
+	})
+
+	t.Run("subscribes to the new events", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		query, err := c.Query(ctx, &v1.QueryRequest{Prefix: prefix, Subscribe: true})
+		assert.Nil(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err := query.Recv()
+			assert.Nil(t, err)
+		}
+
+		third, err := FillStreamWithDummyEvents(c, prefix+"c", 1)
+		assert.Nil(t, err)
+
+		item, err := query.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, third[0], item.EventId)
+		assert.Equal(t, prefix+"c", item.StreamName)
+	})
+}
+
+func Test_readOptionsFor(t *testing.T) {
+	t.Run("reads forwards the events of a backwards read from a position", func(t *testing.T) {
+		options, reversed, err := (&Server{}).readOptionsFor(context.Background(), &v1.ReadStreamRequest{
+			Direction:        v1.ReadDirection_BACKWARD,
+			StartingPosition: 9,
+			Limit:            5,
+		})
+		assert.Nil(t, err)
+		assert.True(t, reversed)
+		assert.Equal(t, simplestore.ReadOptions{StartingPosition: 5, Limit: 5}, options)
+	})
+
+	t.Run("reads forwards down to the beginning of the stream", func(t *testing.T) {
+		options, reversed, err := (&Server{}).readOptionsFor(context.Background(), &v1.ReadStreamRequest{
+			Direction:        v1.ReadDirection_BACKWARD,
+			StartingPosition: 2,
+			Limit:            5,
+		})
+		assert.Nil(t, err)
+		assert.True(t, reversed)
+		assert.Equal(t, simplestore.ReadOptions{StartingPosition: 0, Limit: 3}, options)
+	})
 }
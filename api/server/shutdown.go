@@ -0,0 +1,36 @@
+package server
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"net/http"
+)
+
+// Shutdown stops accepting connections, then waits for the in-flight calls to finish while the
+// subscriptions are ended. Calls still running when the context is done are interrupted.
+func Shutdown(ctx context.Context, grpcServer *grpc.Server, httpServer *http.Server) error {
+	httpErr := make(chan error, 1)
+	go func() {
+		err := httpServer.Shutdown(ctx)
+		if err != nil {
+			_ = httpServer.Close()
+		}
+
+		httpErr <- err
+	}()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		<-grpcStopped
+	}
+
+	return <-httpErr
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/sroze/fossil/api/auth"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/livetail"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	EventStreamContentType = "text/event-stream"
+	LastEventIdHeader      = "Last-Event-ID"
+)
+
+var (
+	// SSEHeartbeatInterval is the time between the heartbeat comments, which keep the idle
+	// connections open through proxies.
+	SSEHeartbeatInterval = 15 * time.Second
+
+	// MaxSSESubscribers is the maximum number of concurrent Server-Sent Events subscribers.
+	MaxSSESubscribers int64 = 1000
+)
+
+func wantsEventStream(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), EventStreamContentType)
+}
+
+// sseStream follows the stream. The id of each event is its stream position, so that it
+// resumes after the `Last-Event-ID`.
+func (s *Server) sseStream(w http.ResponseWriter, r *http.Request, stream string) {
+	err := s.authorize(r.Context(), "GET /streams/", auth.Read, stream)
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	startingPosition := int64(0)
+	if from := r.URL.Query().Get("from"); from != "" {
+		position, err := strconv.ParseInt(from, 10, 64)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid `from`: %s", err))
+			return
+		}
+
+		startingPosition = position
+	}
+
+	if lastEventId := r.Header.Get(LastEventIdHeader); lastEventId != "" {
+		position, err := strconv.ParseInt(lastEventId, 10, 64)
+		if err != nil {
+			writeHTTPError(w, status.Errorf(codes.InvalidArgument, "invalid %s header: %s", LastEventIdHeader, err))
+			return
+		}
+
+		startingPosition = position + 1
+	}
+
+	s.serveEventStream(w, r, livetail.NewStreamReader(s.store, stream), strconv.FormatInt(startingPosition, 10), func(item simplestore.ReadItem) (string, proto.Message) {
+		return strconv.FormatInt(item.EventInStream.Position, 10), &v1.ReadStreamReplyItem{
+			StreamPosition: item.EventInStream.Position,
+			EventId:        item.EventInStream.Event.EventId,
+			EventType:      item.EventInStream.Event.EventType,
+			Payload:        item.EventInStream.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(item.EventInStream.Event.Metadata),
+		}
+	})
+}
+
+// sseQuery follows the streams matching the prefix. The id of each event is the query's cursor
+// after this event, so that it resumes from the `Last-Event-ID`.
+func (s *Server) sseQuery(w http.ResponseWriter, r *http.Request) {
+	err := s.authorize(r.Context(), "GET /query", auth.Read, r.URL.Query().Get("prefix"))
+	if err != nil {
+		writeHTTPError(w, err)
+		return
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if lastEventId := r.Header.Get(LastEventIdHeader); lastEventId != "" {
+		cursor = lastEventId
+	}
+
+	reader := store.NewPrefixReader(s.store, r.URL.Query().Get("prefix"))
+	s.serveEventStream(w, r, reader, cursor, func(item simplestore.ReadItem) (string, proto.Message) {
+		return item.Cursor, &v1.QueryReplyItem{
+			StreamName:     item.EventInStream.Stream,
+			StreamPosition: item.EventInStream.Position,
+			EventId:        item.EventInStream.Event.EventId,
+			EventType:      item.EventInStream.Event.EventType,
+			Payload:        item.EventInStream.Event.Payload,
+			Metadata:       simplestore.WithoutReservedMetadata(item.EventInStream.Event.Metadata),
+			Cursor:         item.Cursor,
+		}
+	})
+}
+
+// serveEventStream sends the events of the live tail until the client goes away, or the server
+// shuts down.
+func (s *Server) serveEventStream(
+	w http.ResponseWriter,
+	r *http.Request,
+	reader livetail.Reader,
+	startingPosition string,
+	toEvent func(item simplestore.ReadItem) (string, proto.Message),
+) {
+	if s.sseSubscribers.Add(1) > MaxSSESubscribers {
+		s.sseSubscribers.Add(-1)
+		writeHTTPError(w, status.Error(codes.Unavailable, "too many subscribers"))
+		return
+	}
+	defer s.sseSubscribers.Add(-1)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeHTTPError(w, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	w.Header().Set("Content-Type", EventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	tail := livetail.NewLiveTail(reader)
+	ch := make(chan simplestore.ReadItem)
+	go tail.Start(startingPosition, ch)
+
+	// The channel is drained so that the live tail can stop.
+	defer func() {
+		tail.Stop()
+		for range ch {
+		}
+	}()
+
+	heartbeat := time.NewTicker(SSEHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.closing:
+			return
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case item, more := <-ch:
+			if !more {
+				return
+			}
+
+			if item.Error != nil {
+				b, _ := json.Marshal(httpError{Error: item.Error.Error()})
+				_, _ = fmt.Fprintf(w, "event: error\ndata: %s\n\n", b)
+				flusher.Flush()
+
+				return
+			}
+
+			if item.EventInStream == nil {
+				continue
+			}
+
+			id, message := toEvent(item)
+			b, err := jsonMarshaller.Marshal(message)
+			if err != nil {
+				return
+			}
+
+			_, _ = fmt.Fprintf(w, "id: %s\ndata: %s\n\n", id, b)
+			flusher.Flush()
+		}
+	}
+}
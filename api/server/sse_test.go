@@ -0,0 +1,206 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sseEvent struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+func Test_SSE(t *testing.T) {
+	s := &Server{store: testStore()}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	subscribeTo := func(server *httptest.Server, path string, lastEventId string) (*http.Response, chan sseEvent, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		assert.Nil(t, err)
+		request.Header.Set("Accept", EventStreamContentType)
+		if lastEventId != "" {
+			request.Header.Set(LastEventIdHeader, lastEventId)
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		assert.Nil(t, err)
+
+		events := make(chan sseEvent, 100)
+		go func() {
+			defer close(events)
+
+			scanner := bufio.NewScanner(response.Body)
+			event := sseEvent{}
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case line == "":
+					events <- event
+					event = sseEvent{}
+				case strings.HasPrefix(line, ":"):
+					event.comment = strings.TrimSpace(line[1:])
+				case strings.HasPrefix(line, "id: "):
+					event.id = line[4:]
+				case strings.HasPrefix(line, "event: "):
+					event.event = line[7:]
+				case strings.HasPrefix(line, "data: "):
+					event.data = line[6:]
+				}
+			}
+		}()
+
+		return response, events, cancel
+	}
+
+	subscribe := func(path string, lastEventId string) (*http.Response, chan sseEvent, context.CancelFunc) {
+		return subscribeTo(server, path, lastEventId)
+	}
+
+	next := func(events chan sseEvent) sseEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return sseEvent{}
+		}
+	}
+
+	write := func(stream string, count int) []string {
+		eventIds := make([]string, count)
+		events := make([]simplestore.Event, count)
+		for i := range events {
+			eventIds[i] = uuid.NewString()
+			events[i] = simplestore.Event{EventId: eventIds[i], EventType: "AnEventType", Payload: []byte("foo")}
+		}
+
+		_, err := s.store.Write(context.Background(), []simplestore.AppendToStream{
+			{Stream: stream, Events: events},
+		})
+		assert.Nil(t, err)
+
+		return eventIds
+	}
+
+	t.Run("follows a stream", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		eventIds := write(stream, 2)
+
+		response, events, cancel := subscribe("/streams/"+stream, "")
+		defer cancel()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, EventStreamContentType, response.Header.Get("Content-Type"))
+
+		for i, eventId := range eventIds {
+			event := next(events)
+			assert.Equal(t, fmt.Sprintf("%d", i), event.id)
+
+			item := &v1.ReadStreamReplyItem{}
+			assert.Nil(t, protojson.Unmarshal([]byte(event.data), item))
+			assert.Equal(t, eventId, item.EventId)
+		}
+
+		liveEventIds := write(stream, 1)
+		event := next(events)
+		assert.Equal(t, "2", event.id)
+		assert.Contains(t, event.data, liveEventIds[0])
+	})
+
+	t.Run("resumes after the last event id", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		eventIds := write(stream, 3)
+
+		_, events, cancel := subscribe("/streams/"+stream, "1")
+		defer cancel()
+
+		event := next(events)
+		assert.Equal(t, "2", event.id)
+		assert.Contains(t, event.data, eventIds[2])
+	})
+
+	t.Run("rejects an invalid last event id", func(t *testing.T) {
+		response, _, cancel := subscribe("/streams/Foo/"+uuid.NewString(), "not-a-position")
+		defer cancel()
+
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	t.Run("sends heartbeats", func(t *testing.T) {
+		previousInterval := SSEHeartbeatInterval
+		SSEHeartbeatInterval = 10 * time.Millisecond
+		defer func() { SSEHeartbeatInterval = previousInterval }()
+
+		_, events, cancel := subscribe("/streams/Foo/"+uuid.NewString(), "")
+		defer cancel()
+
+		event := next(events)
+		assert.Equal(t, "heartbeat", event.comment)
+	})
+
+	t.Run("limits the number of subscribers", func(t *testing.T) {
+		previousMax := MaxSSESubscribers
+		MaxSSESubscribers = 1
+		defer func() { MaxSSESubscribers = previousMax }()
+
+		// A dedicated server, so that the subscribers of the other tests are not counted.
+		limitedServer := httptest.NewServer((&Server{store: s.store}).HTTPHandler())
+		defer limitedServer.Close()
+
+		response, _, cancel := subscribeTo(limitedServer, "/streams/Foo/"+uuid.NewString(), "")
+		defer cancel()
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		response, _, cancelSecond := subscribeTo(limitedServer, "/streams/Foo/"+uuid.NewString(), "")
+		defer cancelSecond()
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	})
+
+	t.Run("follows the streams matching a prefix, with cursors as ids", func(t *testing.T) {
+		prefix := "Bar/" + uuid.NewString() + "/"
+		var eventIds []string
+		for i := 0; i < 3; i++ {
+			eventIds = append(eventIds, write(fmt.Sprintf("%s%d", prefix, i), 1)...)
+		}
+
+		_, events, cancel := subscribe("/query?prefix="+prefix, "")
+		defer cancel()
+
+		var items []*v1.QueryReplyItem
+		var ids []string
+		for range eventIds {
+			event := next(events)
+
+			item := &v1.QueryReplyItem{}
+			assert.Nil(t, protojson.Unmarshal([]byte(event.data), item))
+			assert.Equal(t, item.Cursor, event.id)
+			assert.True(t, strings.HasPrefix(item.StreamName, prefix))
+
+			items = append(items, item)
+			ids = append(ids, event.id)
+		}
+
+		cancel()
+
+		_, resumedEvents, cancelResumed := subscribe("/query?prefix="+prefix, ids[0])
+		defer cancelResumed()
+
+		event := next(resumedEvents)
+		assert.Equal(t, ids[1], event.id)
+		assert.Contains(t, event.data, items[1].EventId)
+	})
+}
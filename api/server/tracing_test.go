@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"github.com/google/uuid"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/tracing"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"testing"
+)
+
+func Test_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+
+	client, end := testClient()
+	defer end()
+
+	// The client's trace is propagated in the call's metadata.
+	clientSpan := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0f, 0x55, 0x11},
+		SpanID:     trace.SpanID{0x01},
+		TraceFlags: trace.FlagsSampled,
+	})
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpanContext(context.Background(), clientSpan), carrier)
+	ctx := metadata.NewOutgoingContext(context.Background(), metadata.New(carrier))
+
+	stream := "Foo/" + uuid.NewString()
+	_, err := client.Append(ctx, &v1.AppendRequest{
+		StreamName: stream,
+		Events:     []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+	})
+	assert.Nil(t, err)
+
+	spansByName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID() == clientSpan.TraceID() {
+			spansByName[span.Name()] = span
+		}
+	}
+
+	t.Run("continues the client's trace in the gRPC handler", func(t *testing.T) {
+		span, found := spansByName["fossil.Writer/Append"]
+		assert.True(t, found)
+		assert.Equal(t, clientSpan.SpanID(), span.Parent().SpanID())
+		assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	})
+
+	t.Run("traces the store and segment operations", func(t *testing.T) {
+		write, found := spansByName["store.Write"]
+		assert.True(t, found)
+		assert.Equal(t, spansByName["fossil.Writer/Append"].SpanContext().SpanID(), write.Parent().SpanID())
+		assert.Contains(t, write.Attributes(), tracing.StreamsKey.StringSlice([]string{stream}))
+
+		_, found = spansByName["store.Read"]
+		assert.True(t, found, "the stream's head lookup is traced")
+		_, found = spansByName["simplestore.PrepareKvWrites"]
+		assert.True(t, found)
+	})
+}
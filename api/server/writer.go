@@ -2,32 +2,82 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/sroze/fossil/api/auth"
 	"github.com/sroze/fossil/api/v1"
 	"github.com/sroze/fossil/simplestore"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+var (
+	// MaxMetadataEntries is the maximum number of metadata entries of an event.
+	MaxMetadataEntries = 32
+
+	// MaxMetadataKeySize is the maximum size, in bytes, of an event's metadata key.
+	MaxMetadataKeySize = 128
+
+	// MaxMetadataValueSize is the maximum size, in bytes, of an event's metadata value.
+	MaxMetadataValueSize = 4096
+)
+
 func TransformEvent(request *v1.EventToAppend) (simplestore.Event, error) {
 	if request.EventType == "" {
 		return simplestore.Event{}, status.Errorf(codes.InvalidArgument,
 			"Events must have a type.")
 	}
 
+	err := validateMetadata(request.Metadata)
+	if err != nil {
+		return simplestore.Event{}, err
+	}
+
 	return simplestore.Event{
 		EventId:   request.EventId,
 		EventType: request.EventType,
 		Payload:   request.Payload,
+		Metadata:  request.Metadata,
 	}, nil
 }
 
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxMetadataEntries {
+		return status.Errorf(codes.InvalidArgument,
+			"Events can't have more than %d metadata entries.", MaxMetadataEntries)
+	}
+
+	for k, v := range metadata {
+		if simplestore.IsReservedMetadataKey(k) {
+			return status.Errorf(codes.InvalidArgument,
+				"Metadata key %q is reserved: keys can't start with %q.", k, simplestore.ReservedMetadataPrefix)
+		}
+
+		if len(k) == 0 || len(k) > MaxMetadataKeySize {
+			return status.Errorf(codes.InvalidArgument,
+				"Metadata keys must have between 1 and %d bytes.", MaxMetadataKeySize)
+		}
+
+		if len(v) > MaxMetadataValueSize {
+			return status.Errorf(codes.InvalidArgument,
+				"Metadata value of %q can't have more than %d bytes.", k, MaxMetadataValueSize)
+		}
+	}
+
+	return nil
+}
+
 func TransformEvents(events []*v1.EventToAppend) ([]simplestore.Event, error) {
 	transformed := make([]simplestore.Event, len(events))
 
 	for i, event := range events {
 		transformEvent, err := TransformEvent(event)
 		if err != nil {
+			// Keeps the gRPC code of the error, which is lost when wrapping it.
+			if s, ok := status.FromError(err); ok {
+				return nil, status.Errorf(s.Code(), "error with event #%d: %s", i, s.Message())
+			}
+
 			return nil, fmt.Errorf("error with event #%d: %w", i, err)
 		}
 
@@ -38,6 +88,11 @@ func TransformEvents(events []*v1.EventToAppend) ([]simplestore.Event, error) {
 }
 
 func (s *Server) Append(ctx context.Context, in *v1.AppendRequest) (*v1.AppendReply, error) {
+	err := s.authorize(ctx, v1.Writer_Append_FullMethodName, auth.Write, in.StreamName)
+	if err != nil {
+		return nil, err
+	}
+
 	events, err := TransformEvents(in.Events)
 	if err != nil {
 		return nil, err
@@ -55,10 +110,18 @@ func (s *Server) Append(ctx context.Context, in *v1.AppendRequest) (*v1.AppendRe
 
 	result, err := s.store.Write(ctx, []simplestore.AppendToStream{command})
 	if err != nil {
-		if _, cf := err.(simplestore.StreamConditionFailed); cf {
+		if errors.As(err, &simplestore.StreamConditionFailed{}) {
 			return nil, status.Errorf(codes.FailedPrecondition, err.Error())
 		}
 
+		if errors.As(err, &simplestore.PositionLockTimeoutErr{}) || errors.Is(err, simplestore.PositionLockExpiredErr) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Errorf(codes.DeadlineExceeded, err.Error())
+		}
+
+		if errors.Is(err, simplestore.StoreIsClosedErr{}) {
+			return nil, status.Errorf(codes.Unavailable, err.Error())
+		}
+
 		return nil, err
 	}
 
@@ -5,8 +5,11 @@ import (
 	"github.com/google/uuid"
 	v1 "github.com/sroze/fossil/api/v1"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
+	"strings"
 	"testing"
 )
 
@@ -36,6 +39,116 @@ func Test_writer(t *testing.T) {
 			assert.Fail(t, "expected a status error")
 		}
 	})
+
+	t.Run("an expected position of -1 expects the stream to be empty", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		empty := int64(-1)
+		request := &v1.AppendRequest{
+			StreamName:       stream,
+			Events:           []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+			ExpectedPosition: &empty,
+		}
+
+		reply, err := c.Append(context.Background(), request)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), reply.StreamPosition)
+
+		_, err = c.Append(context.Background(), request)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("an expected position of 0 expects the stream to have a single event", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		_, err := c.Append(context.Background(), &v1.AppendRequest{
+			StreamName: stream,
+			Events: []*v1.EventToAppend{
+				{EventId: uuid.NewString(), EventType: "AnEventType"},
+				{EventId: uuid.NewString(), EventType: "AnEventType"},
+			},
+		})
+		assert.Nil(t, err)
+
+		first := int64(0)
+		_, err = c.Append(context.Background(), &v1.AppendRequest{
+			StreamName:       stream,
+			Events:           []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+			ExpectedPosition: &first,
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("appends at the expected position on a node which did not perform the last write", func(t *testing.T) {
+		var clients []v1.WriterClient
+		for _, node := range testNodes(2) {
+			err, server, addr := NewServer(node, Config{})
+			assert.Nil(t, err)
+			defer server.Stop()
+
+			conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			assert.Nil(t, err)
+			defer conn.Close()
+
+			clients = append(clients, v1.NewWriterClient(conn))
+		}
+
+		stream := "Foo/" + uuid.NewString()
+		appendAt := func(client v1.WriterClient, expectedPosition int64) error {
+			_, err := client.Append(context.Background(), &v1.AppendRequest{
+				StreamName:       stream,
+				Events:           []*v1.EventToAppend{{EventId: uuid.NewString(), EventType: "AnEventType"}},
+				ExpectedPosition: &expectedPosition,
+			})
+
+			return err
+		}
+
+		assert.Nil(t, appendAt(clients[0], -1))
+		assert.Nil(t, appendAt(clients[1], 0))
+		assert.Nil(t, appendAt(clients[0], 1))
+		assert.Equal(t, codes.FailedPrecondition, status.Code(appendAt(clients[1], 1)))
+	})
+
+	t.Run("metadata are stored and read back", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		metadata := map[string]string{
+			"correlation-id": uuid.NewString(),
+			"content-type":   "application/json",
+		}
+
+		_, err := c.Append(context.Background(), &v1.AppendRequest{
+			StreamName: stream,
+			Events: []*v1.EventToAppend{
+				{EventId: uuid.New().String(), EventType: "AnEventType", Payload: []byte("{\"foo\": 123}"), Metadata: metadata},
+			},
+		})
+		assert.Nil(t, err)
+
+		reader, err := c.ReadStream(context.Background(), &v1.ReadStreamRequest{
+			StreamName: stream,
+		})
+		assert.Nil(t, err)
+
+		item, err := reader.Recv()
+		assert.Nil(t, err)
+		assert.Equal(t, metadata, item.Metadata)
+	})
+
+	t.Run("rejects invalid metadata", func(t *testing.T) {
+		for _, metadata := range []map[string]string{
+			{"$stream": "Bar/123"},
+			{"key": strings.Repeat("a", MaxMetadataValueSize+1)},
+			{strings.Repeat("a", MaxMetadataKeySize+1): "value"},
+		} {
+			_, err := c.Append(context.Background(), &v1.AppendRequest{
+				StreamName: "Foo/" + uuid.NewString(),
+				Events: []*v1.EventToAppend{
+					{EventId: uuid.New().String(), EventType: "AnEventType", Payload: []byte("{}"), Metadata: metadata},
+				},
+			})
+
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		}
+	})
 }
 
 // FillStreamWithDummyEvents fills a stream with dummy events.
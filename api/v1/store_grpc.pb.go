@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion7
 const (
 	Writer_Append_FullMethodName     = "/fossil.Writer/Append"
 	Writer_ReadStream_FullMethodName = "/fossil.Writer/ReadStream"
+	Writer_Query_FullMethodName      = "/fossil.Writer/Query"
 )
 
 // WriterClient is the client API for Writer service.
@@ -29,6 +30,7 @@ const (
 type WriterClient interface {
 	Append(ctx context.Context, in *AppendRequest, opts ...grpc.CallOption) (*AppendReply, error)
 	ReadStream(ctx context.Context, in *ReadStreamRequest, opts ...grpc.CallOption) (Writer_ReadStreamClient, error)
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (Writer_QueryClient, error)
 }
 
 type writerClient struct {
@@ -80,12 +82,45 @@ func (x *writerReadStreamClient) Recv() (*ReadStreamReplyItem, error) {
 	return m, nil
 }
 
+func (c *writerClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (Writer_QueryClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Writer_ServiceDesc.Streams[1], Writer_Query_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &writerQueryClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Writer_QueryClient interface {
+	Recv() (*QueryReplyItem, error)
+	grpc.ClientStream
+}
+
+type writerQueryClient struct {
+	grpc.ClientStream
+}
+
+func (x *writerQueryClient) Recv() (*QueryReplyItem, error) {
+	m := new(QueryReplyItem)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // WriterServer is the server API for Writer service.
 // All implementations must embed UnimplementedWriterServer
 // for forward compatibility
 type WriterServer interface {
 	Append(context.Context, *AppendRequest) (*AppendReply, error)
 	ReadStream(*ReadStreamRequest, Writer_ReadStreamServer) error
+	Query(*QueryRequest, Writer_QueryServer) error
 	mustEmbedUnimplementedWriterServer()
 }
 
@@ -99,6 +134,9 @@ func (UnimplementedWriterServer) Append(context.Context, *AppendRequest) (*Appen
 func (UnimplementedWriterServer) ReadStream(*ReadStreamRequest, Writer_ReadStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method ReadStream not implemented")
 }
+func (UnimplementedWriterServer) Query(*QueryRequest, Writer_QueryServer) error {
+	return status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
 func (UnimplementedWriterServer) mustEmbedUnimplementedWriterServer() {}
 
 // UnsafeWriterServer may be embedded to opt out of forward compatibility for this service.
@@ -151,6 +189,27 @@ func (x *writerReadStreamServer) Send(m *ReadStreamReplyItem) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Writer_Query_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WriterServer).Query(m, &writerQueryServer{stream})
+}
+
+type Writer_QueryServer interface {
+	Send(*QueryReplyItem) error
+	grpc.ServerStream
+}
+
+type writerQueryServer struct {
+	grpc.ServerStream
+}
+
+func (x *writerQueryServer) Send(m *QueryReplyItem) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Writer_ServiceDesc is the grpc.ServiceDesc for Writer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -169,6 +228,314 @@ var Writer_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _Writer_ReadStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Query",
+			Handler:       _Writer_Query_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/v1/store.proto",
+}
+
+const (
+	Admin_ListSegments_FullMethodName      = "/fossil.Admin/ListSegments"
+	Admin_CreateSegment_FullMethodName     = "/fossil.Admin/CreateSegment"
+	Admin_SplitSegment_FullMethodName      = "/fossil.Admin/SplitSegment"
+	Admin_ReplaceSegment_FullMethodName    = "/fossil.Admin/ReplaceSegment"
+	Admin_GetStreamLocation_FullMethodName = "/fossil.Admin/GetStreamLocation"
+	Admin_WatchTopology_FullMethodName     = "/fossil.Admin/WatchTopology"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsReply, error)
+	CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*CreateSegmentReply, error)
+	SplitSegment(ctx context.Context, in *SplitSegmentRequest, opts ...grpc.CallOption) (*SplitSegmentReply, error)
+	ReplaceSegment(ctx context.Context, in *ReplaceSegmentRequest, opts ...grpc.CallOption) (*ReplaceSegmentReply, error)
+	GetStreamLocation(ctx context.Context, in *GetStreamLocationRequest, opts ...grpc.CallOption) (*GetStreamLocationReply, error)
+	WatchTopology(ctx context.Context, in *WatchTopologyRequest, opts ...grpc.CallOption) (Admin_WatchTopologyClient, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListSegments(ctx context.Context, in *ListSegmentsRequest, opts ...grpc.CallOption) (*ListSegmentsReply, error) {
+	out := new(ListSegmentsReply)
+	err := c.cc.Invoke(ctx, Admin_ListSegments_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CreateSegment(ctx context.Context, in *CreateSegmentRequest, opts ...grpc.CallOption) (*CreateSegmentReply, error) {
+	out := new(CreateSegmentReply)
+	err := c.cc.Invoke(ctx, Admin_CreateSegment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SplitSegment(ctx context.Context, in *SplitSegmentRequest, opts ...grpc.CallOption) (*SplitSegmentReply, error) {
+	out := new(SplitSegmentReply)
+	err := c.cc.Invoke(ctx, Admin_SplitSegment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReplaceSegment(ctx context.Context, in *ReplaceSegmentRequest, opts ...grpc.CallOption) (*ReplaceSegmentReply, error) {
+	out := new(ReplaceSegmentReply)
+	err := c.cc.Invoke(ctx, Admin_ReplaceSegment_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetStreamLocation(ctx context.Context, in *GetStreamLocationRequest, opts ...grpc.CallOption) (*GetStreamLocationReply, error) {
+	out := new(GetStreamLocationReply)
+	err := c.cc.Invoke(ctx, Admin_GetStreamLocation_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) WatchTopology(ctx context.Context, in *WatchTopologyRequest, opts ...grpc.CallOption) (Admin_WatchTopologyClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], Admin_WatchTopology_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &adminWatchTopologyClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Admin_WatchTopologyClient interface {
+	Recv() (*TopologyEvent, error)
+	grpc.ClientStream
+}
+
+type adminWatchTopologyClient struct {
+	grpc.ClientStream
+}
+
+func (x *adminWatchTopologyClient) Recv() (*TopologyEvent, error) {
+	m := new(TopologyEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility
+type AdminServer interface {
+	ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsReply, error)
+	CreateSegment(context.Context, *CreateSegmentRequest) (*CreateSegmentReply, error)
+	SplitSegment(context.Context, *SplitSegmentRequest) (*SplitSegmentReply, error)
+	ReplaceSegment(context.Context, *ReplaceSegmentRequest) (*ReplaceSegmentReply, error)
+	GetStreamLocation(context.Context, *GetStreamLocationRequest) (*GetStreamLocationReply, error)
+	WatchTopology(*WatchTopologyRequest, Admin_WatchTopologyServer) error
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedAdminServer struct {
+}
+
+func (UnimplementedAdminServer) ListSegments(context.Context, *ListSegmentsRequest) (*ListSegmentsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSegments not implemented")
+}
+func (UnimplementedAdminServer) CreateSegment(context.Context, *CreateSegmentRequest) (*CreateSegmentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSegment not implemented")
+}
+func (UnimplementedAdminServer) SplitSegment(context.Context, *SplitSegmentRequest) (*SplitSegmentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SplitSegment not implemented")
+}
+func (UnimplementedAdminServer) ReplaceSegment(context.Context, *ReplaceSegmentRequest) (*ReplaceSegmentReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplaceSegment not implemented")
+}
+func (UnimplementedAdminServer) GetStreamLocation(context.Context, *GetStreamLocationRequest) (*GetStreamLocationReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStreamLocation not implemented")
+}
+func (UnimplementedAdminServer) WatchTopology(*WatchTopologyRequest, Admin_WatchTopologyServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTopology not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_ListSegments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSegmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSegments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListSegments_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSegments(ctx, req.(*ListSegmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CreateSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CreateSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateSegment(ctx, req.(*CreateSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SplitSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SplitSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SplitSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SplitSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SplitSegment(ctx, req.(*SplitSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReplaceSegment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplaceSegmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReplaceSegment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ReplaceSegment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReplaceSegment(ctx, req.(*ReplaceSegmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetStreamLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStreamLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetStreamLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetStreamLocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetStreamLocation(ctx, req.(*GetStreamLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_WatchTopology_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTopologyRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).WatchTopology(m, &adminWatchTopologyServer{stream})
+}
+
+type Admin_WatchTopologyServer interface {
+	Send(*TopologyEvent) error
+	grpc.ServerStream
+}
+
+type adminWatchTopologyServer struct {
+	grpc.ServerStream
+}
+
+func (x *adminWatchTopologyServer) Send(m *TopologyEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "fossil.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSegments",
+			Handler:    _Admin_ListSegments_Handler,
+		},
+		{
+			MethodName: "CreateSegment",
+			Handler:    _Admin_CreateSegment_Handler,
+		},
+		{
+			MethodName: "SplitSegment",
+			Handler:    _Admin_SplitSegment_Handler,
+		},
+		{
+			MethodName: "ReplaceSegment",
+			Handler:    _Admin_ReplaceSegment_Handler,
+		},
+		{
+			MethodName: "GetStreamLocation",
+			Handler:    _Admin_GetStreamLocation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTopology",
+			Handler:       _Admin_WatchTopology_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/v1/store.proto",
 }
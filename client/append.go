@@ -0,0 +1,154 @@
+package client
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+)
+
+// AppendLookBack is the number of events read back from the end of the stream, after a failed
+// append, to find whether it was written nonetheless.
+var AppendLookBack = 100
+
+// Append appends the events to the stream, and returns the position of the last one. The events
+// without an id are given one, so that the append can be retried without duplicating them.
+func (c *Client) Append(ctx context.Context, stream string, events ...simplestore.Event) (int64, error) {
+	return c.append(ctx, stream, nil, events)
+}
+
+// AppendAt appends the events to the stream if its last event is at the expected position, `-1`
+// expecting the stream to be empty. Otherwise, it returns `StreamConditionFailed`.
+func (c *Client) AppendAt(ctx context.Context, stream string, expectedPosition int64, events ...simplestore.Event) (int64, error) {
+	return c.append(ctx, stream, &expectedPosition, events)
+}
+
+// AppendMessages appends the messages to the stream, encoded with the client's codec.
+func (c *Client) AppendMessages(ctx context.Context, stream string, messages ...interface{}) (int64, error) {
+	events, err := c.Encode(messages...)
+	if err != nil {
+		return -1, err
+	}
+
+	return c.Append(ctx, stream, events...)
+}
+
+// Encode returns the events of the messages, encoded with the client's codec.
+func (c *Client) Encode(messages ...interface{}) ([]simplestore.Event, error) {
+	if c.codec == nil {
+		return nil, ErrNoCodec
+	}
+
+	events := make([]simplestore.Event, len(messages))
+	for i, message := range messages {
+		event, err := c.codec.Serialize(message)
+		if err != nil {
+			return nil, err
+		}
+
+		events[i] = event
+	}
+
+	return events, nil
+}
+
+func (c *Client) append(ctx context.Context, stream string, expectedPosition *int64, events []simplestore.Event) (int64, error) {
+	request := &v1.AppendRequest{
+		StreamName:       stream,
+		Events:           make([]*v1.EventToAppend, len(events)),
+		ExpectedPosition: expectedPosition,
+	}
+
+	for i, event := range events {
+		if event.EventId == "" {
+			event.EventId = uuid.NewString()
+		}
+
+		request.Events[i] = &v1.EventToAppend{
+			EventId:   event.EventId,
+			EventType: event.EventType,
+			Payload:   event.Payload,
+			Metadata:  event.Metadata,
+		}
+	}
+
+	for retry := 0; ; retry++ {
+		var err error
+		if retry > 0 {
+			// The previous attempts might have been written before failing.
+			var position int64
+			var found bool
+			position, found, err = c.findAppended(ctx, request)
+			if found {
+				return position, nil
+			}
+		}
+
+		if err == nil {
+			var reply *v1.AppendReply
+			reply, err = c.pool.writer().Append(ctx, request)
+			if err == nil {
+				return reply.StreamPosition, nil
+			}
+
+			if status.Code(err) == codes.FailedPrecondition && expectedPosition != nil {
+				return -1, StreamConditionFailed{
+					Stream:                 stream,
+					ExpectedStreamPosition: *expectedPosition,
+				}
+			}
+		}
+
+		if !isRetryable(err) || retry >= c.retryPolicy.MaxRetries {
+			if retry > 0 {
+				return -1, RetriedErr{Retries: retry, Err: err}
+			}
+
+			return -1, err
+		}
+
+		if err := sleep(ctx, c.retryPolicy.backoff(retry+1)); err != nil {
+			return -1, err
+		}
+	}
+}
+
+// findAppended returns the position of the request's last event, if it was found amongst the
+// last events of the stream.
+func (c *Client) findAppended(ctx context.Context, request *v1.AppendRequest) (int64, bool, error) {
+	if len(request.Events) == 0 {
+		return -1, false, nil
+	}
+
+	lastEventId := request.Events[len(request.Events)-1].EventId
+
+	// The read is cancelled once the event is found.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reader, err := c.pool.writer().ReadStream(ctx, &v1.ReadStreamRequest{
+		StreamName: request.StreamName,
+		Direction:  v1.ReadDirection_BACKWARD,
+		FromEnd:    true,
+		Limit:      uint32(AppendLookBack),
+	})
+	if err != nil {
+		return -1, false, err
+	}
+
+	for {
+		item, err := reader.Recv()
+		if err == io.EOF {
+			return -1, false, nil
+		} else if err != nil {
+			return -1, false, err
+		}
+
+		if item.EndOfStream == nil && item.EventId == lastEventId {
+			return item.StreamPosition, true, nil
+		}
+	}
+}
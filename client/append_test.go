@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/server"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+type orderPlaced struct {
+	OrderId string
+}
+
+// readAll returns the events of the stream.
+func readAll(t *testing.T, c *Client, stream string) []RecordedEvent {
+	it := c.ReadStream(context.Background(), stream, ReadOptions{})
+	defer it.Close()
+
+	var events []RecordedEvent
+	for it.Next() {
+		events = append(events, it.Event())
+	}
+	assert.Nil(t, it.Err())
+
+	return events
+}
+
+func Test_Append(t *testing.T) {
+	s := testStore(t)
+	_, addr := testServer(t, s, server.Config{})
+	c := testClient(t, Config{Addresses: []string{addr}})
+	ctx := context.Background()
+
+	t.Run("appends the events and returns the position of the last one", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		position, err := c.Append(ctx, stream, anEvent(), anEvent())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), position)
+
+		position, err = c.Append(ctx, stream, simplestore.Event{EventType: "AnEventType"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), position)
+
+		events := readAll(t, c, stream)
+		assert.Equal(t, 3, len(events))
+		assert.NotEmpty(t, events[2].Event.EventId)
+	})
+
+	t.Run("appends at the expected position", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		position, err := c.AppendAt(ctx, stream, -1, anEvent())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), position)
+
+		_, err = c.AppendAt(ctx, stream, -1, anEvent())
+		assert.Equal(t, StreamConditionFailed{Stream: stream, ExpectedStreamPosition: -1}, err)
+
+		position, err = c.AppendAt(ctx, stream, 0, anEvent())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), position)
+
+		_, err = c.AppendAt(ctx, stream, 0, anEvent())
+		assert.True(t, errors.As(err, &StreamConditionFailed{}))
+	})
+
+	t.Run("retries without duplicating the events", func(t *testing.T) {
+		// The first append is written, but its reply is lost.
+		lostReplies := 1
+		c := testClient(t, Config{
+			Addresses: []string{addr},
+			DialOptions: []grpc.DialOption{grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				err := invoker(ctx, method, req, reply, cc, opts...)
+				if method == v1.Writer_Append_FullMethodName && lostReplies > 0 {
+					lostReplies--
+					return status.Error(codes.Unavailable, "the reply was lost")
+				}
+
+				return err
+			})},
+		})
+
+		stream := "Foo/" + uuid.NewString()
+		position, err := c.Append(ctx, stream, anEvent(), simplestore.Event{EventType: "AnEventType"})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), position)
+		assert.Equal(t, 2, len(readAll(t, c, stream)))
+	})
+
+	t.Run("gives up after the retries", func(t *testing.T) {
+		c := testClient(t, Config{
+			Addresses: []string{addr},
+			DialOptions: []grpc.DialOption{grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+				return status.Error(codes.Unavailable, "the node is unavailable")
+			})},
+		})
+		c.SetRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+
+		stream := "Foo/" + uuid.NewString()
+		_, err := c.Append(ctx, stream, anEvent())
+		assert.Equal(t, 2, err.(RetriedErr).Retries)
+		assert.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)))
+		assert.Empty(t, readAll(t, c, stream))
+	})
+
+	t.Run("appends messages with the codec", func(t *testing.T) {
+		jsonCodec, err := codec.NewJSONCodec([]codec.JSONTypePair{
+			{TypeName: "OrderPlaced", ActualType: &orderPlaced{}},
+		})
+		assert.Nil(t, err)
+		c := testClient(t, Config{Addresses: []string{addr}, Codec: jsonCodec})
+
+		stream := "Foo/" + uuid.NewString()
+		_, err = c.AppendMessages(ctx, stream, &orderPlaced{OrderId: "1234"})
+		assert.Nil(t, err)
+
+		it := c.ReadStream(ctx, stream, ReadOptions{})
+		defer it.Close()
+		assert.True(t, it.Next())
+		assert.Equal(t, "OrderPlaced", it.Event().Event.EventType)
+
+		message, err := it.Message()
+		assert.Nil(t, err)
+		assert.Equal(t, &orderPlaced{OrderId: "1234"}, message)
+
+		_, err = testClient(t, Config{Addresses: []string{addr}}).AppendMessages(ctx, stream, &orderPlaced{})
+		assert.Equal(t, ErrNoCodec, err)
+	})
+}
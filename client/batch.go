@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"github.com/sroze/fossil/simplestore"
+	"time"
+)
+
+type batchRequest struct {
+	ctx    context.Context
+	events []simplestore.Event
+	result chan batchResult
+}
+
+type batchResult struct {
+	position int64
+	err      error
+}
+
+// AppendBatched appends the events to the stream as `Append` does, but concurrent appends to the
+// same stream are grouped in a single call. It returns the position of the last event.
+// If the context is cancelled once the events were handed over to the batch, they might still
+// be appended.
+func (c *Client) AppendBatched(ctx context.Context, stream string, events ...simplestore.Event) (int64, error) {
+	request := batchRequest{
+		ctx:    ctx,
+		events: events,
+		result: make(chan batchResult, 1),
+	}
+
+	c.batchesMutex.Lock()
+	pending, running := c.batches[stream]
+	c.batches[stream] = append(pending, request)
+	c.batchesMutex.Unlock()
+
+	if !running {
+		go c.runBatches(stream)
+	}
+
+	select {
+	case result := <-request.result:
+		return result.position, result.err
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	}
+}
+
+// runBatches sends the batches of the stream until there is no more pending append.
+func (c *Client) runBatches(stream string) {
+	for {
+		if c.batchLingerTime > 0 {
+			time.Sleep(c.batchLingerTime)
+		}
+
+		batch := c.collectBatch(stream)
+		if len(batch) == 0 {
+			return
+		}
+
+		c.sendBatch(stream, batch)
+	}
+}
+
+// collectBatch returns the pending appends of the stream, up to the maximum batch size. When
+// there are none, the stream is removed from the batches.
+func (c *Client) collectBatch(stream string) []batchRequest {
+	c.batchesMutex.Lock()
+	defer c.batchesMutex.Unlock()
+
+	pending := c.batches[stream]
+	if len(pending) == 0 {
+		delete(c.batches, stream)
+		return nil
+	}
+
+	size, count := 0, 0
+	for count < len(pending) && (count == 0 || size+len(pending[count].events) <= c.batchMaxSize) {
+		size += len(pending[count].events)
+		count++
+	}
+
+	c.batches[stream] = pending[count:]
+
+	return pending[:count]
+}
+
+// sendBatch appends the events of the batch's requests which are still waiting, in a single call.
+func (c *Client) sendBatch(stream string, batch []batchRequest) {
+	var requests []batchRequest
+	var events []simplestore.Event
+	for _, request := range batch {
+		if request.ctx.Err() == nil {
+			requests = append(requests, request)
+			events = append(events, request.events...)
+		}
+	}
+
+	if len(requests) == 0 {
+		return
+	}
+
+	// The batch outlives the requests' contexts, which might be cancelled while it is sent.
+	position, err := c.append(context.Background(), stream, nil, events)
+	for i := len(requests) - 1; i >= 0; i-- {
+		requests[i].result <- batchResult{position: position, err: err}
+
+		// The position of the previous request's last event is before this request's events.
+		if err == nil {
+			position -= int64(len(requests[i].events))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/server"
+	v1 "github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_AppendBatched(t *testing.T) {
+	s := testStore(t)
+	_, addr := testServer(t, s, server.Config{})
+
+	var calls atomic.Int64
+	c := testClient(t, Config{
+		Addresses:       []string{addr},
+		BatchLingerTime: 50 * time.Millisecond,
+		BatchMaxSize:    10,
+		DialOptions: []grpc.DialOption{grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			if method == v1.Writer_Append_FullMethodName {
+				calls.Add(1)
+			}
+
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})},
+	})
+
+	t.Run("groups the concurrent appends and returns the position of their events", func(t *testing.T) {
+		stream := "Foo/" + uuid.NewString()
+		events := make([]simplestore.Event, 20)
+		positions := make([]int64, 20)
+
+		wg := sync.WaitGroup{}
+		for i := range events {
+			events[i] = anEvent()
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+
+				var err error
+				positions[i], err = c.AppendBatched(context.Background(), stream, events[i])
+				assert.Nil(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		// The batches are limited in size.
+		assert.GreaterOrEqual(t, calls.Load(), int64(2))
+		assert.Less(t, calls.Load(), int64(20))
+
+		recorded := readAll(t, c, stream)
+		assert.Equal(t, 20, len(recorded))
+		for i, event := range events {
+			assert.Equal(t, event.EventId, recorded[positions[i]].Event.EventId)
+		}
+	})
+
+	t.Run("sends the appends of different streams separately", func(t *testing.T) {
+		first, err := c.AppendBatched(context.Background(), "Foo/"+uuid.NewString(), anEvent(), anEvent())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), first)
+
+		second, err := c.AppendBatched(context.Background(), "Foo/"+uuid.NewString(), anEvent())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), second)
+	})
+}
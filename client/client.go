@@ -0,0 +1,116 @@
+// Package client is the Go client of Fossil's gRPC API. It spreads the calls across the nodes,
+// retries the appends without duplicating their events, and reads the streams with iterators.
+package client
+
+import (
+	"context"
+	"github.com/sroze/fossil/api/auth"
+	"github.com/sroze/fossil/eskit/codec"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	// Addresses of the Fossil nodes, as `host:port`.
+	Addresses []string
+
+	// TransportCredentials of the connections, which are insecure when not set.
+	TransportCredentials credentials.TransportCredentials
+
+	// APIKey, when set, is sent with every call.
+	APIKey string
+
+	// DialOptions are added to the options of the connections.
+	DialOptions []grpc.DialOption
+
+	// Codec encodes the messages appended with `AppendMessages`, and decodes the events read
+	// with `Iterator.Message`.
+	Codec codec.Codec
+
+	// BatchMaxSize is the maximum number of events appended in a single call by `AppendBatched`.
+	// Defaults to `DefaultBatchMaxSize`.
+	BatchMaxSize int
+
+	// BatchLingerTime is how long `AppendBatched` waits for more appends to arrive before
+	// sending a batch. With `0`, only the appends that arrived while the previous batch was
+	// being sent are grouped together.
+	BatchLingerTime time.Duration
+}
+
+const DefaultBatchMaxSize = 100
+
+type Client struct {
+	pool        *pool
+	codec       codec.Codec
+	retryPolicy RetryPolicy
+
+	batchMaxSize    int
+	batchLingerTime time.Duration
+
+	// batches are the appends waiting to be sent by `AppendBatched`, by stream. A stream has an
+	// entry while its batches are being sent.
+	batchesMutex sync.Mutex
+	batches      map[string][]batchRequest
+}
+
+func NewClient(config Config) (*Client, error) {
+	transportCredentials := config.TransportCredentials
+	if transportCredentials == nil {
+		transportCredentials = insecure.NewCredentials()
+	}
+
+	options := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if config.APIKey != "" {
+		options = append(options, grpc.WithPerRPCCredentials(apiKeyCredentials{
+			key:                      config.APIKey,
+			requireTransportSecurity: config.TransportCredentials != nil,
+		}))
+	}
+
+	p, err := dialPool(config.Addresses, append(options, config.DialOptions...))
+	if err != nil {
+		return nil, err
+	}
+
+	batchMaxSize := config.BatchMaxSize
+	if batchMaxSize <= 0 {
+		batchMaxSize = DefaultBatchMaxSize
+	}
+
+	return &Client{
+		pool:            p,
+		codec:           config.Codec,
+		retryPolicy:     DefaultRetryPolicy,
+		batchMaxSize:    batchMaxSize,
+		batchLingerTime: config.BatchLingerTime,
+		batches:         map[string][]batchRequest{},
+	}, nil
+}
+
+// SetRetryPolicy configures how the calls are retried when they fail because a node is unavailable.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// Close closes the connections to the nodes.
+func (c *Client) Close() error {
+	return c.pool.Close()
+}
+
+// apiKeyCredentials sends the API key with every call.
+type apiKeyCredentials struct {
+	key                      string
+	requireTransportSecurity bool
+}
+
+func (c apiKeyCredentials) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{strings.ToLower(auth.APIKeyHeader): c.key}, nil
+}
+
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return c.requireTransportSecurity
+}
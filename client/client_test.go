@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/auth"
+	"github.com/sroze/fossil/api/server"
+	"github.com/sroze/fossil/kv/foundationdb"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store"
+	"github.com/sroze/fossil/store/segments"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net"
+	"testing"
+)
+
+func testStore(t *testing.T) *store.Store {
+	fdb.MustAPIVersion(720)
+	kv := foundationdb.NewStore(fdb.MustOpenDatabase("../fdb.cluster"))
+	s := store.NewStore(kv, uuid.New())
+	assert.Nil(t, s.Start())
+	t.Cleanup(s.Stop)
+
+	// Create a segment that covers everything.
+	_, err := s.GetTopologyManager().Create(segments.NewSegment(
+		segments.NewPrefixRange(""),
+	))
+	assert.Nil(t, err)
+
+	return s
+}
+
+// testServer starts a node serving the store, and returns its address.
+func testServer(t *testing.T, s *store.Store, config server.Config) (*grpc.Server, string) {
+	err, grpcServer, addr := server.NewServer(s, config)
+	assert.Nil(t, err)
+	t.Cleanup(grpcServer.Stop)
+
+	return grpcServer, addr.String()
+}
+
+func testClient(t *testing.T, config Config) *Client {
+	c, err := NewClient(config)
+	assert.Nil(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func anEvent() simplestore.Event {
+	return simplestore.Event{
+		EventId:   uuid.NewString(),
+		EventType: "AnEventType",
+		Payload:   []byte("{\"foo\": 123}"),
+	}
+}
+
+func Test_Client(t *testing.T) {
+	s := testStore(t)
+
+	t.Run("requires an address", func(t *testing.T) {
+		_, err := NewClient(Config{})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("spreads the calls across the nodes, skipping those which are down", func(t *testing.T) {
+		_, addr := testServer(t, s, server.Config{})
+
+		// A node which is down.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		down := listener.Addr().String()
+		assert.Nil(t, listener.Close())
+
+		c := testClient(t, Config{Addresses: []string{down, addr}})
+		for i := 0; i < 5; i++ {
+			_, err := c.Append(context.Background(), "Foo/"+uuid.NewString(), anEvent())
+			assert.Nil(t, err)
+		}
+	})
+
+	t.Run("sends the API key", func(t *testing.T) {
+		_, addr := testServer(t, s, server.Config{
+			Authorizer: auth.NewAuthorizer(auth.NewAPIKeyAuthenticator(map[string]string{"secret": "orders"}), auth.ACL{
+				{Principal: "orders", Prefix: "Foo/", Permissions: []auth.Permission{auth.Write}},
+			}),
+		})
+
+		_, err := testClient(t, Config{Addresses: []string{addr}, APIKey: "secret"}).Append(context.Background(), "Foo/"+uuid.NewString(), anEvent())
+		assert.Nil(t, err)
+
+		_, err = testClient(t, Config{Addresses: []string{addr}, APIKey: "another"}).Append(context.Background(), "Foo/"+uuid.NewString(), anEvent())
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
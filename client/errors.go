@@ -0,0 +1,34 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StreamConditionFailed is returned when the stream's last event is not at the expected position,
+// as `simplestore.StreamConditionFailed` is by the store.
+type StreamConditionFailed struct {
+	Stream                 string
+	ExpectedStreamPosition int64
+}
+
+func (e StreamConditionFailed) Error() string {
+	return fmt.Sprintf("failed expectation to find stream %s at position #%d", e.Stream, e.ExpectedStreamPosition)
+}
+
+// RetriedErr is returned when a call failed after having been retried. The other errors are
+// the gRPC status errors of the calls.
+type RetriedErr struct {
+	Retries int
+	Err     error
+}
+
+func (e RetriedErr) Error() string {
+	return fmt.Sprintf("call failed after %d retries: %s", e.Retries, e.Err)
+}
+
+func (e RetriedErr) Unwrap() error {
+	return e.Err
+}
+
+var ErrNoCodec = errors.New("the client has no codec")
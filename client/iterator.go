@@ -0,0 +1,295 @@
+package client
+
+import (
+	"context"
+	"github.com/sroze/fossil/api/v1"
+	"github.com/sroze/fossil/simplestore"
+	"io"
+)
+
+// RecordedEvent is an event read from a stream.
+type RecordedEvent struct {
+	Stream   string
+	Position int64
+	Event    simplestore.Event
+
+	// Cursor from which a query resumes right after this event. It is empty for the events
+	// read from a single stream.
+	Cursor string
+}
+
+type ReadOptions struct {
+	// The position of the first event to read. When reading backwards, it is the highest one.
+	StartingPosition int64
+
+	// Reads from the end of the stream, ignoring `StartingPosition`. Forward reads start `Limit`
+	// events before the end.
+	FromEnd bool
+
+	Backwards bool
+
+	// The maximum number of events to read. When `0`, all the events are read.
+	Limit int
+}
+
+// receiver returns the next event of a read, or nil for the items which are not events.
+type receiver func() (*RecordedEvent, error)
+
+// Iterator reads events, one at a time:
+//
+//	it := c.ReadStream(ctx, stream, client.ReadOptions{})
+//	defer it.Close()
+//
+//	for it.Next() {
+//		event := it.Event()
+//	}
+//
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type Iterator struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *Client
+
+	// open starts the read on a node, from where the iterator is.
+	open func(ctx context.Context, writer v1.WriterClient) (receiver, error)
+
+	// Subscriptions are resumed when their read fails, from where they were.
+	subscription bool
+
+	recv    receiver
+	current RecordedEvent
+	retries int
+	done    bool
+	err     error
+}
+
+func (c *Client) newIterator(ctx context.Context, subscription bool, open func(ctx context.Context, writer v1.WriterClient) (receiver, error)) *Iterator {
+	it := &Iterator{
+		parent:       ctx,
+		client:       c,
+		open:         open,
+		subscription: subscription,
+	}
+
+	it.ctx, it.cancel = context.WithCancel(ctx)
+
+	return it
+}
+
+// ReadStream reads the events of the stream, up to its end.
+func (c *Client) ReadStream(ctx context.Context, stream string, options ReadOptions) *Iterator {
+	request := &v1.ReadStreamRequest{
+		StreamName:       stream,
+		StartingPosition: options.StartingPosition,
+		FromEnd:          options.FromEnd,
+		Limit:            uint32(options.Limit),
+	}
+	if options.Backwards {
+		request.Direction = v1.ReadDirection_BACKWARD
+	}
+
+	return c.newIterator(ctx, false, func(ctx context.Context, writer v1.WriterClient) (receiver, error) {
+		return readStream(ctx, writer, request)
+	})
+}
+
+// Subscribe reads the events of the stream from the starting position, and then the events
+// appended to it, until the context is done or the iterator is closed.
+func (c *Client) Subscribe(ctx context.Context, stream string, startingPosition int64) *Iterator {
+	request := &v1.ReadStreamRequest{
+		StreamName:       stream,
+		StartingPosition: startingPosition,
+		Subscribe:        true,
+	}
+
+	return c.newIterator(ctx, true, func(ctx context.Context, writer v1.WriterClient) (receiver, error) {
+		recv, err := readStream(ctx, writer, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return func() (*RecordedEvent, error) {
+			event, err := recv()
+			if event != nil {
+				request.StartingPosition = event.Position + 1
+			}
+
+			return event, err
+		}, nil
+	})
+}
+
+// Query reads the events of the streams matching the prefix, from the cursor (or from the
+// beginning when empty).
+func (c *Client) Query(ctx context.Context, prefix string, cursor string) *Iterator {
+	request := &v1.QueryRequest{
+		Prefix: prefix,
+		Cursor: cursor,
+	}
+
+	return c.newIterator(ctx, false, func(ctx context.Context, writer v1.WriterClient) (receiver, error) {
+		return query(ctx, writer, request)
+	})
+}
+
+// SubscribeToQuery reads the events of the streams matching the prefix from the cursor, and then
+// the events appended to them, until the context is done or the iterator is closed.
+func (c *Client) SubscribeToQuery(ctx context.Context, prefix string, cursor string) *Iterator {
+	request := &v1.QueryRequest{
+		Prefix:    prefix,
+		Cursor:    cursor,
+		Subscribe: true,
+	}
+
+	return c.newIterator(ctx, true, func(ctx context.Context, writer v1.WriterClient) (receiver, error) {
+		recv, err := query(ctx, writer, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return func() (*RecordedEvent, error) {
+			event, err := recv()
+			if event != nil {
+				request.Cursor = event.Cursor
+			}
+
+			return event, err
+		}, nil
+	})
+}
+
+func readStream(ctx context.Context, writer v1.WriterClient, request *v1.ReadStreamRequest) (receiver, error) {
+	reader, err := writer.ReadStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*RecordedEvent, error) {
+		item, err := reader.Recv()
+		if err != nil || item.EndOfStream != nil {
+			return nil, err
+		}
+
+		return &RecordedEvent{
+			Stream:   request.StreamName,
+			Position: item.StreamPosition,
+			Event: simplestore.Event{
+				EventId:   item.EventId,
+				EventType: item.EventType,
+				Payload:   item.Payload,
+				Metadata:  item.Metadata,
+			},
+		}, nil
+	}, nil
+}
+
+func query(ctx context.Context, writer v1.WriterClient, request *v1.QueryRequest) (receiver, error) {
+	reader, err := writer.Query(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() (*RecordedEvent, error) {
+		item, err := reader.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		return &RecordedEvent{
+			Stream:   item.StreamName,
+			Position: item.StreamPosition,
+			Event: simplestore.Event{
+				EventId:   item.EventId,
+				EventType: item.EventType,
+				Payload:   item.Payload,
+				Metadata:  item.Metadata,
+			},
+			Cursor: item.Cursor,
+		}, nil
+	}, nil
+}
+
+// Next reads the next event, returning false once there are no more or the read failed.
+func (it *Iterator) Next() bool {
+	for !it.done && it.err == nil {
+		if it.recv == nil {
+			recv, err := it.open(it.ctx, it.client.pool.writer())
+			if err != nil {
+				it.fail(err)
+				continue
+			}
+
+			it.recv = recv
+		}
+
+		event, err := it.recv()
+		if err == io.EOF {
+			it.done = true
+		} else if err != nil {
+			it.recv = nil
+			it.fail(err)
+		} else if event != nil {
+			it.current = *event
+			it.retries = 0
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// fail ends the iteration with the error, unless it is a subscription which can be resumed.
+func (it *Iterator) fail(err error) {
+	if it.parent.Err() != nil {
+		it.err = it.parent.Err()
+		return
+	} else if it.ctx.Err() != nil {
+		// The iterator was closed.
+		it.done = true
+		return
+	}
+
+	if it.subscription && isRetryable(err) {
+		it.retries++
+		if sleep(it.ctx, it.client.retryPolicy.backoff(it.retries)) != nil {
+			it.fail(err)
+		}
+
+		return
+	}
+
+	if it.retries > 0 {
+		err = RetriedErr{Retries: it.retries, Err: err}
+	}
+
+	it.err = err
+}
+
+// Event returns the event read by the last call to `Next`.
+func (it *Iterator) Event() RecordedEvent {
+	return it.current
+}
+
+// Message returns the message of the event read by the last call to `Next`, decoded with the
+// client's codec.
+func (it *Iterator) Message() (interface{}, error) {
+	if it.client.codec == nil {
+		return nil, ErrNoCodec
+	}
+
+	return it.client.codec.Deserialize(it.current.Event)
+}
+
+// Err returns the error which ended the iteration, if any.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Close stops the read.
+func (it *Iterator) Close() {
+	it.cancel()
+}
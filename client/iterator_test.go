@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/api/server"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func eventIdsOf(events []RecordedEvent) []string {
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.Event.EventId
+	}
+
+	return ids
+}
+
+// next returns the next event of the iterator, failing the test if there is none in time.
+func next(t *testing.T, it *Iterator) RecordedEvent {
+	ch := make(chan bool, 1)
+	go func() { ch <- it.Next() }()
+
+	select {
+	case more := <-ch:
+		assert.True(t, more, "expected an event, got error: %v", it.Err())
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event instead of a timeout")
+	}
+
+	return it.Event()
+}
+
+func Test_Iterator(t *testing.T) {
+	s := testStore(t)
+	_, addr := testServer(t, s, server.Config{})
+	c := testClient(t, Config{Addresses: []string{addr}})
+	ctx := context.Background()
+
+	prefix := "Foo/" + uuid.NewString() + "/"
+	stream := prefix + "a"
+	var eventIds []string
+	for i := 0; i < 10; i++ {
+		event := anEvent()
+		eventIds = append(eventIds, event.EventId)
+
+		_, err := c.Append(ctx, stream, event)
+		assert.Nil(t, err)
+	}
+
+	t.Run("reads the stream", func(t *testing.T) {
+		for _, tc := range []struct {
+			name     string
+			options  ReadOptions
+			expected []string
+		}{
+			{name: "all the events", options: ReadOptions{}, expected: eventIds},
+			{name: "from a position, with a limit", options: ReadOptions{StartingPosition: 2, Limit: 3}, expected: eventIds[2:5]},
+			{name: "backwards from the end", options: ReadOptions{Backwards: true, FromEnd: true, Limit: 2}, expected: []string{eventIds[9], eventIds[8]}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				it := c.ReadStream(ctx, stream, tc.options)
+				defer it.Close()
+
+				var events []RecordedEvent
+				for it.Next() {
+					events = append(events, it.Event())
+				}
+				assert.Nil(t, it.Err())
+				assert.Equal(t, tc.expected, eventIdsOf(events))
+			})
+		}
+	})
+
+	t.Run("queries the streams and resumes from a cursor", func(t *testing.T) {
+		other := anEvent()
+		_, err := c.Append(ctx, prefix+"b", other)
+		assert.Nil(t, err)
+
+		it := c.Query(ctx, prefix, "")
+		defer it.Close()
+
+		var events []RecordedEvent
+		for it.Next() {
+			events = append(events, it.Event())
+		}
+		assert.Nil(t, it.Err())
+		assert.ElementsMatch(t, append([]string{other.EventId}, eventIds...), eventIdsOf(events))
+
+		resumed := c.Query(ctx, prefix, events[4].Cursor)
+		defer resumed.Close()
+
+		var resumedEvents []RecordedEvent
+		for resumed.Next() {
+			resumedEvents = append(resumedEvents, resumed.Event())
+		}
+		assert.Nil(t, resumed.Err())
+		assert.Equal(t, eventIdsOf(events[5:]), eventIdsOf(resumedEvents))
+	})
+
+	t.Run("subscribes to the stream", func(t *testing.T) {
+		it := c.Subscribe(ctx, stream, 8)
+		defer it.Close()
+
+		assert.Equal(t, eventIds[8], next(t, it).Event.EventId)
+		assert.Equal(t, eventIds[9], next(t, it).Event.EventId)
+
+		appended := anEvent()
+		_, err := c.Append(ctx, stream, appended)
+		assert.Nil(t, err)
+
+		event := next(t, it)
+		assert.Equal(t, appended.EventId, event.Event.EventId)
+		assert.Equal(t, int64(10), event.Position)
+		eventIds = append(eventIds, appended.EventId)
+	})
+
+	t.Run("ends the subscription when it is closed", func(t *testing.T) {
+		it := c.Subscribe(ctx, stream, 0)
+		next(t, it)
+		it.Close()
+
+		for it.Next() {
+		}
+		assert.Nil(t, it.Err())
+	})
+
+	t.Run("resumes the subscriptions on another node", func(t *testing.T) {
+		first, firstAddr := testServer(t, s, server.Config{})
+		_, secondAddr := testServer(t, s, server.Config{})
+
+		// The first call of a client is on its first node.
+		subscriber := testClient(t, Config{Addresses: []string{firstAddr, secondAddr}})
+		subscriber.SetRetryPolicy(RetryPolicy{MaxRetries: 5, InitialBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+
+		streamSubscription := subscriber.Subscribe(ctx, stream, int64(len(eventIds)-1))
+		defer streamSubscription.Close()
+		assert.Equal(t, eventIds[len(eventIds)-1], next(t, streamSubscription).Event.EventId)
+
+		querySubscription := subscriber.SubscribeToQuery(ctx, prefix, "")
+		defer querySubscription.Close()
+		for i := 0; i < len(eventIds)+1; i++ {
+			next(t, querySubscription)
+		}
+
+		first.Stop()
+
+		appended := anEvent()
+		_, err := c.Append(ctx, stream, appended)
+		assert.Nil(t, err)
+
+		assert.Equal(t, appended.EventId, next(t, streamSubscription).Event.EventId)
+		assert.Equal(t, appended.EventId, next(t, querySubscription).Event.EventId)
+	})
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sroze/fossil/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"sync/atomic"
+)
+
+// pool spreads the calls across the connections to the nodes, in turn.
+type pool struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+func dialPool(addresses []string, options []grpc.DialOption) (*pool, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("at least one address is required")
+	}
+
+	p := &pool{conns: make([]*grpc.ClientConn, 0, len(addresses))}
+	for _, address := range addresses {
+		conn, err := grpc.Dial(address, options...)
+		if err != nil {
+			_ = p.Close()
+			return nil, fmt.Errorf("could not dial %s: %w", address, err)
+		}
+
+		p.conns = append(p.conns, conn)
+	}
+
+	return p, nil
+}
+
+// writer returns the client of the next connection. The connections which are failing are
+// skipped, unless they all are.
+func (p *pool) writer() v1.WriterClient {
+	for range p.conns {
+		conn := p.pick()
+		if state := conn.GetState(); state != connectivity.TransientFailure && state != connectivity.Shutdown {
+			return v1.NewWriterClient(conn)
+		}
+	}
+
+	return v1.NewWriterClient(p.pick())
+}
+
+func (p *pool) pick() *grpc.ClientConn {
+	return p.conns[(p.next.Add(1)-1)%uint64(len(p.conns))]
+}
+
+func (p *pool) Close() error {
+	var err error
+	for _, conn := range p.conns {
+		if closeErr := conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// loadConfigFile sets the flags from a JSON file of values, by flag name (e.g.
+// `{"listen-address": "0.0.0.0", "keepalive-time": "1m"}`). Flags set on the command line
+// take precedence.
+func loadConfigFile(cmd *cobra.Command, path string) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// Numbers are kept as written, rather than formatted as floats.
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	values := map[string]interface{}{}
+	err = decoder.Decode(&values)
+	if err != nil {
+		return fmt.Errorf("invalid configuration file: %w", err)
+	}
+
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown configuration %q", name)
+		}
+
+		if flag.Changed {
+			continue
+		}
+
+		err = cmd.Flags().Set(name, fmt.Sprint(value))
+		if err != nil {
+			return fmt.Errorf("invalid configuration %q: %w", name, err)
+		}
+	}
+
+	return nil
+}
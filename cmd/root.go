@@ -5,6 +5,7 @@ import (
 	"github.com/apple/foundationdb/bindings/go/src/fdb"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+	"github.com/sroze/fossil/kv"
 	"github.com/sroze/fossil/kv/foundationdb"
 	"github.com/sroze/fossil/store"
 	"os"
@@ -30,8 +31,8 @@ func Execute() {
 
 func getStore() *store.Store {
 	fdb.MustAPIVersion(720)
-	kv := foundationdb.NewStore(fdb.MustOpenDatabase("fdb.cluster"))
-	s := store.NewStore(kv, uuid.MustParse(storeId))
+	backend := kv.Instrument("foundationdb", foundationdb.NewStore(fdb.MustOpenDatabase("fdb.cluster")))
+	s := store.NewStore(backend, uuid.MustParse(storeId))
 
 	return s
 }
@@ -1,22 +1,54 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/sroze/fossil/api/auth"
 	"github.com/sroze/fossil/api/server"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store"
 	"github.com/sroze/fossil/store/segments"
 	"github.com/sroze/fossil/store/topology"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var automatedInit bool
+var httpPort int
+var authConfigPath string
+var configPath string
+var shutdownTimeout time.Duration
+var tracingEnabled bool
+var listenConfig server.Config
+var tlsConfig server.TLSConfig
 
 var runCmd = &cobra.Command{
 	Use:   "run",
 	Short: "Run the Fossil server",
 	Run: func(cmd *cobra.Command, args []string) {
+		if configPath != "" {
+			err := loadConfigFile(cmd, configPath)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		if tracingEnabled {
+			shutdownTracing, err := setUpTracing(context.Background())
+			if err != nil {
+				panic(err)
+			}
+
+			defer func() {
+				if err := shutdownTracing(context.Background()); err != nil {
+					fmt.Printf("Could not flush the traces: %s\n", err)
+				}
+			}()
+		}
+
 		s := getStore()
 		err := s.Start()
 		if err != nil {
@@ -43,22 +75,77 @@ var runCmd = &cobra.Command{
 			}
 		}
 
-		err, server, a := server.NewServer(s, 8001)
+		if authConfigPath != "" {
+			config, err := auth.LoadConfig(authConfigPath)
+			if err != nil {
+				panic(err)
+			}
+
+			listenConfig.Authorizer, err = auth.NewAuthorizerFromConfig(config)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+			listenConfig.TLS = &tlsConfig
+		}
+
+		httpConfig := listenConfig
+		httpConfig.Port = httpPort
+		err, httpServer, _ := server.NewHTTPServer(s, httpConfig)
+		if err != nil {
+			panic(err)
+		}
+
+		err, grpcServer, a := server.NewServer(s, listenConfig)
 		if err != nil {
 			panic(err)
 		}
 
-		defer server.Stop()
-		fmt.Printf("server listening at %v", a)
+		fmt.Printf("server listening at %v\n", a)
 
 		stop := make(chan os.Signal, 1)
 		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 		<-stop
+
+		// Drain the in-flight appends and subscriptions before closing the store.
+		fmt.Println("Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		err = server.Shutdown(ctx, grpcServer, httpServer)
+		if err != nil {
+			fmt.Printf("Shutdown did not complete: %s\n", err)
+		}
+
+		s.Stop()
 	},
 }
 
 func init() {
 	runCmd.Flags().BoolVar(&automatedInit, "automated-init", true, "automatically initialize the store if it does not exist")
+	runCmd.Flags().StringVar(&configPath, "config", "", "JSON file of flag values, by flag name; flags set on the command line take precedence")
+	runCmd.Flags().StringVar(&listenConfig.ListenAddress, "listen-address", "127.0.0.1", "address the APIs bind to")
+	runCmd.Flags().IntVar(&listenConfig.Port, "port", 8001, "port of the gRPC API")
+	runCmd.Flags().IntVar(&httpPort, "http-port", 8002, "port of the HTTP/JSON API")
+	runCmd.Flags().StringVar(&tlsConfig.CertFile, "tls-cert", "", "TLS certificate file; TLS is served when set")
+	runCmd.Flags().StringVar(&tlsConfig.KeyFile, "tls-key", "", "TLS private key file")
+	runCmd.Flags().StringVar(&tlsConfig.ClientCAFile, "tls-client-ca", "", "CA file verifying the client certificates")
+	runCmd.Flags().BoolVar(&tlsConfig.RequireClientCert, "tls-require-client-cert", false, "reject the clients without a certificate verified by the client CA")
+	runCmd.Flags().IntVar(&listenConfig.MaxMessageSize, "max-message-size", 4*1024*1024, "maximum size, in bytes, of the received and sent messages")
+	runCmd.Flags().DurationVar(&listenConfig.Keepalive.Time, "keepalive-time", 2*time.Hour, "time after which idle connections are pinged")
+	runCmd.Flags().DurationVar(&listenConfig.Keepalive.Timeout, "keepalive-timeout", 20*time.Second, "time after which pinged connections are closed")
+	runCmd.Flags().DurationVar(&listenConfig.Keepalive.MinTime, "keepalive-min-time", 5*time.Minute, "minimum time clients must wait between pings")
+	runCmd.Flags().BoolVar(&listenConfig.Keepalive.PermitWithoutStream, "keepalive-permit-without-stream", false, "allow the clients' pings when there are no active streams")
+	runCmd.Flags().BoolVar(&listenConfig.Reflection, "grpc-reflection", false, "register the gRPC reflection service, describing the APIs to their callers")
+	runCmd.Flags().DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long to wait for the in-flight calls to finish when shutting down")
+	runCmd.Flags().DurationVar(&server.HealthCheckInterval, "health-check-interval", server.HealthCheckInterval, "how often the readiness reported by the gRPC health service is checked")
+	runCmd.Flags().BoolVar(&tracingEnabled, "tracing", false, "export OpenTelemetry traces with OTLP/HTTP, configured with the standard OTEL_EXPORTER_OTLP_* environment variables")
+	runCmd.Flags().BoolVar(&store.TraceContextInEvents, "trace-context-in-events", false, "record the trace context of the writes in their events' metadata")
+	runCmd.Flags().StringVar(&authConfigPath, "auth-config", "", "JSON file configuring the authentication and the ACL of the APIs; everything is allowed without it")
+	runCmd.Flags().IntVar(&simplestore.GroupCommitMaxBatchSize, "group-commit-max-batch-size", simplestore.GroupCommitMaxBatchSize, "maximum number of concurrent writes grouped in a single transaction, per segment")
+	runCmd.Flags().DurationVar(&simplestore.GroupCommitLingerTime, "group-commit-linger", simplestore.GroupCommitLingerTime, "how long to wait for more concurrent writes before committing a batch")
 
 	rootCmd.AddCommand(runCmd)
 }
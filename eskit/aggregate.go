@@ -0,0 +1,141 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"github.com/sroze/fossil/simplestore"
+)
+
+// AggregateMaxRetries is the number of times a command is decided again when the aggregate's
+// stream was written by someone else in the meantime.
+var AggregateMaxRetries = 3
+
+type DecideFunc[S any, C any] func(state S, command C) ([]interface{}, error)
+
+// Aggregate describes how an aggregate's state is built from its events (`Evolve`) and which
+// events a command results in (`Decide`).
+type Aggregate[S any, C any] struct {
+	InitialState S
+	Evolve       EvolveFunc[S]
+	Decide       DecideFunc[S, C]
+}
+
+// Repository loads aggregates from their stream and handles commands against them.
+type Repository[S any, C any] struct {
+	rw        *ReaderWriter
+	aggregate Aggregate[S, C]
+
+	snapshots         SnapshotStore[S]
+	snapshotFrequency int64
+}
+
+func NewRepository[S any, C any](
+	rw *ReaderWriter,
+	aggregate Aggregate[S, C],
+) *Repository[S, C] {
+	return &Repository[S, C]{
+		rw:        rw,
+		aggregate: aggregate,
+	}
+}
+
+// SetSnapshots makes the repository load aggregates from their latest snapshot, and save a
+// snapshot every `frequency` events.
+func (r *Repository[S, C]) SetSnapshots(snapshots SnapshotStore[S], frequency int64) {
+	r.snapshots = snapshots
+	r.snapshotFrequency = frequency
+}
+
+// Load reads the aggregate's stream and returns its projection, which carries both the state
+// and the position of the last event.
+func (r *Repository[S, C]) Load(ctx context.Context, stream string) (*Projection[S], error) {
+	if r.snapshots != nil {
+		return NewProjectionFromSnapshot(ctx, r.rw, r.snapshots, stream, r.aggregate.InitialState, r.aggregate.Evolve)
+	}
+
+	projection := NewProjection(r.aggregate.InitialState, r.aggregate.Evolve)
+	err := replayStream(ctx, r.rw, stream, projection)
+	if err != nil {
+		return nil, err
+	}
+
+	return projection, nil
+}
+
+// Handle loads the aggregate, decides the events resulting from the command and appends them
+// to the stream, expecting it to still be at the loaded position. If the stream was written in
+// the meantime, the command is decided again with the up-to-date state, up to `AggregateMaxRetries`
+// times. It returns the state of the aggregate once the events were written.
+func (r *Repository[S, C]) Handle(ctx context.Context, stream string, command C) (S, error) {
+	for retries := 0; ; retries++ {
+		projection, err := r.Load(ctx, stream)
+		if err != nil {
+			return r.aggregate.InitialState, err
+		}
+
+		events, err := r.aggregate.Decide(projection.GetState(), command)
+		if err != nil || len(events) == 0 {
+			return projection.GetState(), err
+		}
+
+		previousPosition := projection.GetPosition()
+		err = r.append(ctx, stream, projection, events)
+		if err == nil {
+			if r.snapshots != nil && shouldSnapshot(r.snapshotFrequency, previousPosition, projection.GetPosition()) {
+				// A snapshot that could not be saved only means that more events will be replayed
+				// when loading the aggregate, so the error is ignored.
+				_ = r.snapshots.Save(ctx, stream, Snapshot[S]{
+					State:    projection.GetState(),
+					Position: projection.GetPosition(),
+				})
+			}
+
+			return projection.GetState(), nil
+		}
+
+		var conditionFailed simplestore.StreamConditionFailed
+		if !errors.As(err, &conditionFailed) || retries >= AggregateMaxRetries {
+			return projection.GetState(), err
+		}
+	}
+}
+
+func (r *Repository[S, C]) append(ctx context.Context, stream string, projection *Projection[S], events []interface{}) error {
+	serializedEvents := make([]simplestore.Event, len(events))
+	for i, event := range events {
+		serializedEvent, err := r.rw.codec.Serialize(event)
+		if err != nil {
+			return err
+		}
+
+		serializedEvents[i] = serializedEvent
+	}
+
+	_, err := r.rw.store.Write(ctx, []simplestore.AppendToStream{
+		{
+			Stream: stream,
+			Events: serializedEvents,
+			Condition: &simplestore.AppendCondition{
+				WriteAtPosition: projection.GetPosition() + 1,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// The events are evolved in their decoded form, as they would be when loading the aggregate.
+	for _, serializedEvent := range serializedEvents {
+		event, err := r.rw.codec.Deserialize(serializedEvent)
+		if err != nil {
+			return err
+		}
+
+		err = projection.Apply(event, projection.GetPosition())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type appendCommand struct {
+	S string
+}
+
+var errAlreadyAppended = errors.New("already appended")
+
+func decideStringAppend(state stringAppendState, command appendCommand) ([]interface{}, error) {
+	if len(state) > 0 && string(state[len(state)-1:]) == command.S {
+		return nil, errAlreadyAppended
+	}
+
+	return []interface{}{appendEvent{S: command.S}}, nil
+}
+
+func Test_Repository(t *testing.T) {
+	rw := NewReaderWriter(NewInMemoryStore(), codec.NewGobCodec(appendEvent{}))
+	repository := NewRepository(rw, Aggregate[stringAppendState, appendCommand]{
+		InitialState: "a",
+		Evolve:       evolveStringAppend,
+		Decide:       decideStringAppend,
+	})
+
+	t.Run("handles commands and loads the resulting state", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+
+		state, err := repository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("ab"), state)
+
+		state, err = repository.Handle(context.Background(), stream, appendCommand{S: "c"})
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("abc"), state)
+
+		projection, err := repository.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("abc"), projection.GetState())
+		assert.Equal(t, int64(1), projection.GetPosition())
+	})
+
+	t.Run("returns the decision error without writing", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+
+		_, err := repository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.Nil(t, err)
+
+		state, err := repository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.Equal(t, errAlreadyAppended, err)
+		assert.Equal(t, stringAppendState("ab"), state)
+
+		projection, err := repository.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), projection.GetPosition())
+	})
+
+	t.Run("decides again when the stream was written concurrently", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		decisions := 0
+
+		concurrentRepository := NewRepository(rw, Aggregate[stringAppendState, appendCommand]{
+			InitialState: "a",
+			Evolve:       evolveStringAppend,
+			Decide: func(state stringAppendState, command appendCommand) ([]interface{}, error) {
+				decisions++
+				if decisions == 1 {
+					_, err := repository.Handle(context.Background(), stream, appendCommand{S: "z"})
+					assert.Nil(t, err)
+				}
+
+				return decideStringAppend(state, command)
+			},
+		})
+
+		state, err := concurrentRepository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, decisions)
+		assert.Equal(t, stringAppendState("azb"), state)
+	})
+
+	t.Run("gives up after too many concurrent writes", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		decisions := 0
+
+		concurrentRepository := NewRepository(rw, Aggregate[stringAppendState, appendCommand]{
+			InitialState: "a",
+			Evolve:       evolveStringAppend,
+			Decide: func(state stringAppendState, command appendCommand) ([]interface{}, error) {
+				decisions++
+				_, err := rw.Write([]EventToWrite{
+					{Stream: stream, Event: appendEvent{S: "z"}},
+				})
+				assert.Nil(t, err)
+
+				return decideStringAppend(state, command)
+			},
+		})
+
+		_, err := concurrentRepository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.IsType(t, simplestore.StreamConditionFailed{}, err)
+		assert.Equal(t, AggregateMaxRetries+1, decisions)
+	})
+
+	t.Run("only one of concurrent creations of the stream succeeds", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		decisions := 0
+		concurrentRepository := NewRepository(rw, Aggregate[stringAppendState, appendCommand]{
+			InitialState: "a",
+			Evolve:       evolveStringAppend,
+			Decide: func(state stringAppendState, command appendCommand) ([]interface{}, error) {
+				decisions++
+				if decisions == 1 {
+					_, err := repository.Handle(context.Background(), stream, appendCommand{S: "b"})
+					assert.Nil(t, err)
+				}
+
+				return decideStringAppend(state, command)
+			},
+		})
+
+		state, err := concurrentRepository.Handle(context.Background(), stream, appendCommand{S: "b"})
+		assert.Equal(t, errAlreadyAppended, err)
+		assert.Equal(t, stringAppendState("ab"), state)
+		assert.Equal(t, 2, decisions)
+
+		projection, err := repository.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), projection.GetPosition())
+	})
+}
@@ -0,0 +1,95 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"github.com/sroze/fossil/kv"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// CheckpointStore persists the position up to which a projection has processed events. The
+// checkpoint is opaque to the store: it can be a stream position or a `store.PositionCursor`.
+type CheckpointStore interface {
+	// Load returns the checkpoint of the projection, or an empty string if there is none.
+	Load(ctx context.Context, name string) (string, error)
+
+	// Save replaces the checkpoint of the projection.
+	Save(ctx context.Context, name string, checkpoint string) error
+}
+
+type KVCheckpointStore struct {
+	kv       kv.KV
+	keySpace string
+}
+
+func NewKVCheckpointStore(kv kv.KV, keySpace string) *KVCheckpointStore {
+	return &KVCheckpointStore{
+		kv:       kv,
+		keySpace: keySpace,
+	}
+}
+
+func (s *KVCheckpointStore) key(name string) []byte {
+	return kv.ConcatBytes([]byte(s.keySpace), []byte("/checkpoints/"), []byte(name))
+}
+
+func (s *KVCheckpointStore) Load(ctx context.Context, name string) (string, error) {
+	value, err := s.kv.Get(ctx, s.key(name))
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+func (s *KVCheckpointStore) Save(ctx context.Context, name string, checkpoint string) error {
+	return s.kv.Write(ctx, []kv.Write{
+		{Key: s.key(name), Value: []byte(checkpoint)},
+	})
+}
+
+// FileCheckpointStore stores each projection's checkpoint in a file of the directory.
+type FileCheckpointStore struct {
+	directory string
+}
+
+func NewFileCheckpointStore(directory string) *FileCheckpointStore {
+	return &FileCheckpointStore{
+		directory: directory,
+	}
+}
+
+func (s *FileCheckpointStore) path(name string) string {
+	return filepath.Join(s.directory, url.PathEscape(name)+".checkpoint")
+}
+
+func (s *FileCheckpointStore) Load(ctx context.Context, name string) (string, error) {
+	b, err := os.ReadFile(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+
+	return string(b), err
+}
+
+func (s *FileCheckpointStore) Save(ctx context.Context, name string, checkpoint string) error {
+	// Writes to a temporary file first so that a crash never leaves a truncated checkpoint.
+	f, err := os.CreateTemp(s.directory, url.PathEscape(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.WriteString(checkpoint)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(name))
+}
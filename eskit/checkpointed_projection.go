@@ -0,0 +1,217 @@
+package eskit
+
+import (
+	"context"
+	"fmt"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/livetail"
+	"github.com/sroze/fossil/simplestore"
+	"strconv"
+	"sync"
+)
+
+// DefaultCheckpointFrequency is the number of events processed between two checkpoints.
+var DefaultCheckpointFrequency = 100
+
+// EventHandler processes an event, usually by writing to an external sink. Events are delivered
+// at least once: the events processed since the last checkpoint are delivered again when the
+// projection restarts, so handlers need to be idempotent.
+type EventHandler func(ctx context.Context, event EventInStream) error
+
+// CheckpointedProjection feeds a handler with the events of a live tail, and checkpoints its
+// progress so that it resumes where it stopped when restarted.
+// Checkpoints are saved every `frequency` events, when reaching the end of the stream, and when
+// stopping the projection.
+type CheckpointedProjection struct {
+	name        string
+	tail        *livetail.LiveTail
+	codec       codec.Codec
+	handler     EventHandler
+	checkpoints CheckpointStore
+	frequency   int
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+
+	// Guarded by `mutex`.
+	mutex                sync.Mutex
+	checkpoint           string
+	savedCheckpoint      string
+	eventsSinceLastSaved int
+	err                  error
+}
+
+func NewCheckpointedProjection(
+	name string,
+	tail *livetail.LiveTail,
+	codec codec.Codec,
+	handler EventHandler,
+	checkpoints CheckpointStore,
+) *CheckpointedProjection {
+	return &CheckpointedProjection{
+		name:        name,
+		tail:        tail,
+		codec:       codec,
+		handler:     handler,
+		checkpoints: checkpoints,
+		frequency:   DefaultCheckpointFrequency,
+	}
+}
+
+// SetCheckpointFrequency sets the number of events processed between two checkpoints. With `1`,
+// the checkpoint is saved after each event.
+func (cp *CheckpointedProjection) SetCheckpointFrequency(frequency int) {
+	cp.frequency = frequency
+}
+
+// Start loads the checkpoint and starts processing the events that follow it.
+func (cp *CheckpointedProjection) Start(ctx context.Context) error {
+	checkpoint, err := cp.checkpoints.Load(ctx, cp.name)
+	if err != nil {
+		return fmt.Errorf("cannot load checkpoint of %s: %w", cp.name, err)
+	}
+
+	if checkpoint == "" {
+		checkpoint = "0"
+	}
+
+	cp.checkpoint = checkpoint
+	cp.savedCheckpoint = checkpoint
+	cp.ctx, cp.ctxCancel = context.WithCancel(context.Background())
+	cp.done = make(chan struct{})
+
+	ch := make(chan simplestore.ReadItem)
+	go cp.process(ch)
+	go cp.tail.Start(checkpoint, ch)
+
+	return nil
+}
+
+func (cp *CheckpointedProjection) process(ch chan simplestore.ReadItem) {
+	defer close(cp.done)
+
+	// The channel is drained even after a failure, so that the live tail can stop.
+	for item := range ch {
+		if cp.Err() != nil {
+			continue
+		}
+
+		var err error
+		if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil {
+			err = cp.handle(*item.EventInStream, item.Cursor)
+		} else if item.EndOfStreamSignal != nil {
+			err = cp.saveCheckpoint()
+		}
+
+		if err != nil {
+			cp.mutex.Lock()
+			cp.err = err
+			cp.mutex.Unlock()
+
+			cp.tail.Stop()
+		}
+	}
+}
+
+func (cp *CheckpointedProjection) handle(item simplestore.EventInStream, cursor string) error {
+	event, err := cp.codec.Deserialize(item.Event)
+	if err != nil {
+		return err
+	}
+
+	err = cp.handler(cp.ctx, EventInStream{
+		Event:    event,
+		Position: item.Position,
+	})
+	if err != nil {
+		return fmt.Errorf("handler failed on event #%d: %w", item.Position, err)
+	}
+
+	// The items read from many streams carry the cursor to resume from, while those read from a
+	// single stream are positioned by their stream position.
+	checkpoint := cursor
+	if checkpoint == "" {
+		checkpoint = strconv.FormatInt(item.Position+1, 10)
+	}
+
+	cp.mutex.Lock()
+	cp.checkpoint = checkpoint
+	cp.eventsSinceLastSaved++
+	shouldSave := cp.eventsSinceLastSaved >= cp.frequency
+	cp.mutex.Unlock()
+
+	if shouldSave {
+		return cp.saveCheckpoint()
+	}
+
+	return nil
+}
+
+func (cp *CheckpointedProjection) saveCheckpoint() error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	if cp.checkpoint == cp.savedCheckpoint {
+		return nil
+	}
+
+	err := cp.checkpoints.Save(cp.ctx, cp.name, cp.checkpoint)
+	if err != nil {
+		return fmt.Errorf("cannot save checkpoint of %s: %w", cp.name, err)
+	}
+
+	cp.savedCheckpoint = cp.checkpoint
+	cp.eventsSinceLastSaved = 0
+
+	return nil
+}
+
+// GetCheckpoint returns the position from which the projection would resume.
+func (cp *CheckpointedProjection) GetCheckpoint() string {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	return cp.checkpoint
+}
+
+// Err returns the error that stopped the projection, if any.
+func (cp *CheckpointedProjection) Err() error {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	return cp.err
+}
+
+// WaitEndOfStream waits for the projection to reach the end of the stream. It returns an error
+// if the projection failed or was stopped before.
+func (cp *CheckpointedProjection) WaitEndOfStream() error {
+	err := cp.tail.WaitEndOfStream()
+	if processErr := cp.Err(); processErr != nil {
+		return processErr
+	}
+
+	return err
+}
+
+// Stop stops the projection and saves the checkpoint of the last processed event. It returns
+// the error that stopped the projection, if any.
+func (cp *CheckpointedProjection) Stop() error {
+	if cp.done == nil {
+		return nil
+	}
+
+	cp.tail.Stop()
+	<-cp.done
+
+	saveErr := cp.saveCheckpoint()
+	cp.ctxCancel()
+
+	if err := cp.Err(); err != nil {
+		return err
+	}
+
+	return saveErr
+}
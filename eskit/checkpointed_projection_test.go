@@ -0,0 +1,116 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/livetail"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_CheckpointedProjection(t *testing.T) {
+	ss := NewInMemoryStore()
+	c := codec.NewGobCodec(appendEvent{})
+	rw := NewReaderWriter(ss, c)
+
+	writeEvents := func(stream string, strings ...string) {
+		for _, s := range strings {
+			_, err := rw.Write([]EventToWrite{
+				{Stream: stream, Event: appendEvent{S: s}},
+			})
+			assert.Nil(t, err)
+		}
+	}
+
+	collectingHandler := func(collected *[]string) EventHandler {
+		return func(ctx context.Context, event EventInStream) error {
+			*collected = append(*collected, event.Event.(*appendEvent).S)
+
+			return nil
+		}
+	}
+
+	t.Run("resumes from the checkpoint", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		checkpoints := NewFileCheckpointStore(t.TempDir())
+		writeEvents(stream, "a", "b", "c")
+
+		var collected []string
+		p := NewCheckpointedProjection(stream, livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, collectingHandler(&collected), checkpoints)
+		assert.Nil(t, p.Start(context.Background()))
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Nil(t, p.Stop())
+		assert.Equal(t, []string{"a", "b", "c"}, collected)
+
+		checkpoint, err := checkpoints.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, "3", checkpoint)
+
+		writeEvents(stream, "d", "e")
+
+		collected = nil
+		p = NewCheckpointedProjection(stream, livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, collectingHandler(&collected), checkpoints)
+		assert.Nil(t, p.Start(context.Background()))
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Nil(t, p.Stop())
+		assert.Equal(t, []string{"d", "e"}, collected)
+	})
+
+	t.Run("checkpoints in batches of events", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		checkpoints := &recordingCheckpointStore{CheckpointStore: NewFileCheckpointStore(t.TempDir())}
+		writeEvents(stream, "a", "b", "c", "d", "e")
+
+		var collected []string
+		p := NewCheckpointedProjection(stream, livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, collectingHandler(&collected), checkpoints)
+		p.SetCheckpointFrequency(2)
+		assert.Nil(t, p.Start(context.Background()))
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Nil(t, p.Stop())
+
+		assert.Equal(t, []string{"2", "4", "5"}, checkpoints.saved)
+	})
+
+	t.Run("delivers the failed event again when restarted", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		checkpoints := NewFileCheckpointStore(t.TempDir())
+		writeEvents(stream, "a", "b", "c")
+
+		handlerErr := errors.New("sink is unavailable")
+		var collected []string
+		p := NewCheckpointedProjection(stream, livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, func(ctx context.Context, event EventInStream) error {
+			if event.Event.(*appendEvent).S == "b" {
+				return handlerErr
+			}
+
+			return collectingHandler(&collected)(ctx, event)
+		}, checkpoints)
+		assert.Nil(t, p.Start(context.Background()))
+		assert.Eventually(t, func() bool {
+			return p.Err() != nil
+		}, time.Second, time.Millisecond)
+		assert.ErrorIs(t, p.Stop(), handlerErr)
+		assert.Equal(t, []string{"a"}, collected)
+
+		p = NewCheckpointedProjection(stream, livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, collectingHandler(&collected), checkpoints)
+		assert.Nil(t, p.Start(context.Background()))
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Nil(t, p.Stop())
+		assert.Equal(t, []string{"a", "b", "c"}, collected)
+	})
+}
+
+type recordingCheckpointStore struct {
+	CheckpointStore
+
+	saved []string
+}
+
+func (s *recordingCheckpointStore) Save(ctx context.Context, name string, checkpoint string) error {
+	s.saved = append(s.saved, checkpoint)
+
+	return s.CheckpointStore.Save(ctx, name, checkpoint)
+}
@@ -9,19 +9,23 @@ type testStruct struct {
 	A string
 }
 
-func Test_GobCodec(t *testing.T) {
-	t.Run("ser/des a simple structure", func(t *testing.T) {
-		c := NewGobCodec(testStruct{})
-		toBeSerDes := testStruct{A: "test"}
+// assertSimpleStructureRoundTrip is shared by the tests of the codecs that support plain structures.
+func assertSimpleStructureRoundTrip(t *testing.T, c Codec) {
+	toBeSerDes := testStruct{A: "test"}
+
+	serialized, err := c.Serialize(toBeSerDes)
+	assert.Nil(t, err)
 
-		serialized, err := c.Serialize(toBeSerDes)
-		assert.Nil(t, err)
+	deserialized, err := c.Deserialize(serialized)
+	assert.Nil(t, err)
 
-		deserialized, err := c.Deserialize(serialized)
-		assert.Nil(t, err)
+	asOriginalType, ok := deserialized.(*testStruct)
+	assert.True(t, ok)
+	assert.Equal(t, toBeSerDes.A, asOriginalType.A)
+}
 
-		asOriginalType, ok := deserialized.(*testStruct)
-		assert.True(t, ok)
-		assert.Equal(t, toBeSerDes.A, asOriginalType.A)
+func Test_GobCodec(t *testing.T) {
+	t.Run("ser/des a simple structure", func(t *testing.T) {
+		assertSimpleStructureRoundTrip(t, NewGobCodec(testStruct{}))
 	})
 }
@@ -0,0 +1,119 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/sroze/fossil/simplestore"
+	"reflect"
+)
+
+const (
+	ContentTypeMetadata = "content-type"
+	JSONContentType     = "application/json"
+)
+
+type JSONTypePair struct {
+	TypeName   string
+	ActualType interface{}
+
+	// Schema is an optional JSON Schema against which the events of this type are validated
+	// when being serialized.
+	Schema string
+}
+
+type jsonType struct {
+	name   string
+	t      reflect.Type
+	schema *jsonschema.Schema
+}
+
+// JSONCodec encodes events as JSON, so that they can be decoded by consumers that are not
+// written in Go. Events are identified by the type names of the registry, rather than their
+// Go type.
+type JSONCodec struct {
+	types []jsonType
+}
+
+func NewJSONCodec(events []JSONTypePair) (Codec, error) {
+	types := make([]jsonType, len(events))
+	for i, pair := range events {
+		types[i] = jsonType{
+			name: pair.TypeName,
+			t:    indirectType(reflect.TypeOf(pair.ActualType)),
+		}
+
+		if pair.Schema != "" {
+			schema, err := jsonschema.CompileString(pair.TypeName+".json", pair.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema for %s: %w", pair.TypeName, err)
+			}
+
+			types[i].schema = schema
+		}
+	}
+
+	return &JSONCodec{
+		types: types,
+	}, nil
+}
+
+func (c *JSONCodec) Serialize(message interface{}) (simplestore.Event, error) {
+	t := indirectType(reflect.TypeOf(message))
+	for _, jt := range c.types {
+		if jt.t != t {
+			continue
+		}
+
+		payload, err := json.Marshal(message)
+		if err != nil {
+			return simplestore.Event{}, err
+		}
+
+		if jt.schema != nil {
+			var decoded interface{}
+			if err := json.Unmarshal(payload, &decoded); err != nil {
+				return simplestore.Event{}, err
+			}
+
+			if err := jt.schema.Validate(decoded); err != nil {
+				return simplestore.Event{}, fmt.Errorf("%s does not match its schema: %w", jt.name, err)
+			}
+		}
+
+		return simplestore.Event{
+			EventId:   uuid.NewString(),
+			EventType: jt.name,
+			Payload:   payload,
+			Metadata: map[string]string{
+				ContentTypeMetadata: JSONContentType,
+			},
+		}, nil
+	}
+
+	return simplestore.Event{}, fmt.Errorf("no event type found for %s when serializing", reflect.TypeOf(message).String())
+}
+
+func (c *JSONCodec) Deserialize(event simplestore.Event) (interface{}, error) {
+	for _, jt := range c.types {
+		if jt.name == event.EventType {
+			target := reflect.New(jt.t).Interface()
+			if err := json.Unmarshal(event.Payload, target); err != nil {
+				return nil, err
+			}
+
+			return target, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no event type found for %s when deserializing", event.EventType)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,62 @@
+package codec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_JSONCodec(t *testing.T) {
+	t.Run("ser/des a simple structure", func(t *testing.T) {
+		c, err := NewJSONCodec([]JSONTypePair{
+			{TypeName: "test", ActualType: testStruct{}},
+		})
+		assert.Nil(t, err)
+
+		assertSimpleStructureRoundTrip(t, c)
+	})
+
+	t.Run("writes the type name, content type and a JSON payload", func(t *testing.T) {
+		c, err := NewJSONCodec([]JSONTypePair{
+			{TypeName: "test", ActualType: &testStruct{}},
+		})
+		assert.Nil(t, err)
+
+		serialized, err := c.Serialize(&testStruct{A: "foo"})
+		assert.Nil(t, err)
+		assert.Equal(t, "test", serialized.EventType)
+		assert.Equal(t, JSONContentType, serialized.Metadata[ContentTypeMetadata])
+		assert.JSONEq(t, `{"A": "foo"}`, string(serialized.Payload))
+	})
+
+	t.Run("fails with unknown types", func(t *testing.T) {
+		c, err := NewJSONCodec([]JSONTypePair{})
+		assert.Nil(t, err)
+
+		_, err = c.Serialize(testStruct{A: "foo"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("validates events against their schema", func(t *testing.T) {
+		c, err := NewJSONCodec([]JSONTypePair{
+			{TypeName: "test", ActualType: testStruct{}, Schema: `{
+				"type": "object",
+				"properties": {"A": {"type": "string", "minLength": 2}},
+				"required": ["A"]
+			}`},
+		})
+		assert.Nil(t, err)
+
+		_, err = c.Serialize(testStruct{A: "foo"})
+		assert.Nil(t, err)
+
+		_, err = c.Serialize(testStruct{A: "f"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("refuses invalid schemas", func(t *testing.T) {
+		_, err := NewJSONCodec([]JSONTypePair{
+			{TypeName: "test", ActualType: testStruct{}, Schema: `{"type": 12}`},
+		})
+		assert.NotNil(t, err)
+	})
+}
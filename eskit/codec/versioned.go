@@ -0,0 +1,150 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/sroze/fossil/simplestore"
+	"strconv"
+)
+
+// SchemaVersionMetadata is the metadata key in which the version of the event's schema is written.
+// Events without this key are considered to be of the first version.
+const SchemaVersionMetadata = "schemaVersion"
+
+// Upcaster transforms an encoded event of the given type from `FromVersion` to the next version.
+// The returned event might have another type, in which case the upcasters of that type are then
+// applied.
+type Upcaster struct {
+	EventType   string
+	FromVersion int
+	Upcast      func(event simplestore.Event) (simplestore.Event, error)
+}
+
+// VersionedCodec writes the schema version of events when serializing them, and upcasts older
+// events to their current version before deserializing them with the underlying codec.
+// The current version of an event type is the one following its latest upcaster, or 1 when
+// it has none.
+type VersionedCodec struct {
+	codec     Codec
+	upcasters map[string]map[int]Upcaster
+}
+
+func NewVersionedCodec(codec Codec, upcasters ...Upcaster) Codec {
+	c := &VersionedCodec{
+		codec:     codec,
+		upcasters: map[string]map[int]Upcaster{},
+	}
+
+	for _, upcaster := range upcasters {
+		if _, ok := c.upcasters[upcaster.EventType]; !ok {
+			c.upcasters[upcaster.EventType] = map[int]Upcaster{}
+		}
+
+		c.upcasters[upcaster.EventType][upcaster.FromVersion] = upcaster
+	}
+
+	return c
+}
+
+func (c *VersionedCodec) Serialize(message interface{}) (simplestore.Event, error) {
+	event, err := c.codec.Serialize(message)
+	if err != nil {
+		return event, err
+	}
+
+	if event.Metadata == nil {
+		event.Metadata = map[string]string{}
+	}
+
+	event.Metadata[SchemaVersionMetadata] = strconv.Itoa(c.currentVersion(event.EventType))
+
+	return event, nil
+}
+
+func (c *VersionedCodec) Deserialize(event simplestore.Event) (interface{}, error) {
+	event, err := c.upcast(event)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.codec.Deserialize(event)
+}
+
+func (c *VersionedCodec) upcast(event simplestore.Event) (simplestore.Event, error) {
+	version, err := schemaVersionOf(event)
+	if err != nil {
+		return event, err
+	}
+
+	for version < c.currentVersion(event.EventType) {
+		upcaster, ok := c.upcasters[event.EventType][version]
+		if !ok {
+			return event, fmt.Errorf("no upcaster for version %d of event type %s", version, event.EventType)
+		}
+
+		upcasted, err := upcaster.Upcast(event)
+		if err != nil {
+			return event, fmt.Errorf("cannot upcast version %d of event type %s: %w", version, event.EventType, err)
+		}
+
+		// The upcasted event is the first version of its new type.
+		version = version + 1
+		if upcasted.EventType != event.EventType {
+			version = 1
+		}
+
+		event = upcasted
+	}
+
+	return event, nil
+}
+
+func (c *VersionedCodec) currentVersion(eventType string) int {
+	version := 1
+	for fromVersion := range c.upcasters[eventType] {
+		if fromVersion+1 > version {
+			version = fromVersion + 1
+		}
+	}
+
+	return version
+}
+
+func schemaVersionOf(event simplestore.Event) (int, error) {
+	version, ok := event.Metadata[SchemaVersionMetadata]
+	if !ok {
+		return 1, nil
+	}
+
+	v, err := strconv.Atoi(version)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q for event type %s", version, event.EventType)
+	}
+
+	return v, nil
+}
+
+// NewGobUpcaster returns an upcaster of gob-encoded events, which decodes the payload as
+// `From`, transforms it, and encodes the result as the payload of the next version.
+func NewGobUpcaster[From any, To any](eventType string, fromVersion int, upcast func(From) To) Upcaster {
+	return Upcaster{
+		EventType:   eventType,
+		FromVersion: fromVersion,
+		Upcast: func(event simplestore.Event) (simplestore.Event, error) {
+			var from From
+			if err := gob.NewDecoder(bytes.NewBuffer(event.Payload)).Decode(&from); err != nil {
+				return event, err
+			}
+
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(upcast(from)); err != nil {
+				return event, err
+			}
+
+			event.Payload = buf.Bytes()
+
+			return event, nil
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// The previous versions of `testStruct`.
+type testStructV1 struct {
+	Name string
+}
+
+type testStructV2 struct {
+	Names []string
+}
+
+func gobEncodedEvent(t *testing.T, eventType string, payload interface{}, metadata map[string]string) simplestore.Event {
+	var buf bytes.Buffer
+	assert.Nil(t, gob.NewEncoder(&buf).Encode(payload))
+
+	return simplestore.Event{
+		EventId:   uuid.NewString(),
+		EventType: eventType,
+		Payload:   buf.Bytes(),
+		Metadata:  metadata,
+	}
+}
+
+func Test_VersionedCodec(t *testing.T) {
+	c := NewVersionedCodec(
+		NewGobCodec(testStruct{}),
+		NewGobUpcaster("codec.testStruct", 1, func(v1 testStructV1) testStructV2 {
+			return testStructV2{Names: []string{v1.Name}}
+		}),
+		NewGobUpcaster("codec.testStruct", 2, func(v2 testStructV2) testStruct {
+			return testStruct{A: strings.Join(v2.Names, ",")}
+		}),
+	)
+
+	t.Run("writes the current schema version", func(t *testing.T) {
+		serialized, err := c.Serialize(testStruct{A: "test"})
+		assert.Nil(t, err)
+		assert.Equal(t, "3", serialized.Metadata[SchemaVersionMetadata])
+
+		deserialized, err := c.Deserialize(serialized)
+		assert.Nil(t, err)
+		assert.Equal(t, &testStruct{A: "test"}, deserialized)
+	})
+
+	t.Run("upcasts events without version through the whole chain", func(t *testing.T) {
+		deserialized, err := c.Deserialize(gobEncodedEvent(t, "codec.testStruct", testStructV1{Name: "foo"}, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, &testStruct{A: "foo"}, deserialized)
+	})
+
+	t.Run("upcasts events from an intermediary version", func(t *testing.T) {
+		deserialized, err := c.Deserialize(gobEncodedEvent(t, "codec.testStruct", testStructV2{Names: []string{"foo", "bar"}}, map[string]string{
+			SchemaVersionMetadata: "2",
+		}))
+		assert.Nil(t, err)
+		assert.Equal(t, &testStruct{A: "foo,bar"}, deserialized)
+	})
+
+	t.Run("fails without upcaster for the event's version", func(t *testing.T) {
+		c := NewVersionedCodec(
+			NewGobCodec(testStruct{}),
+			NewGobUpcaster("codec.testStruct", 2, func(v2 testStructV2) testStruct {
+				return testStruct{A: strings.Join(v2.Names, ",")}
+			}),
+		)
+
+		_, err := c.Deserialize(gobEncodedEvent(t, "codec.testStruct", testStructV1{Name: "foo"}, nil))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,63 @@
+package eskit
+
+import (
+	"context"
+	"github.com/sroze/fossil/simplestore"
+	"strconv"
+)
+
+type SourceItem struct {
+	EventInStream *simplestore.EventInStream
+
+	// Cursor from which a query resumes right after this event.
+	Cursor string
+	Error  error
+}
+
+// EventSource queries the events of the streams matching a prefix, from an opaque cursor. It is
+// implemented for `simplestore.Store` by `SimpleStoreEventSource`, and for `store.Store` by
+// `store.EventSource`.
+type EventSource interface {
+	// Query sends the events written after the cursor (or from the beginning when empty) to the
+	// channel, and closes it once they were all sent.
+	Query(ctx context.Context, prefix string, cursor string, ch chan SourceItem)
+}
+
+type SimpleStoreEventSource struct {
+	store simplestore.Store
+}
+
+func NewSimpleStoreEventSource(store simplestore.Store) *SimpleStoreEventSource {
+	return &SimpleStoreEventSource{
+		store: store,
+	}
+}
+
+func (s *SimpleStoreEventSource) Query(ctx context.Context, prefix string, cursor string, ch chan SourceItem) {
+	defer close(ch)
+
+	startingPosition := int64(0)
+	if cursor != "" {
+		var err error
+		startingPosition, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			ch <- SourceItem{Error: err}
+			return
+		}
+	}
+
+	intermediaryCh := make(chan simplestore.QueryItem)
+	go s.store.Query(ctx, prefix, startingPosition, intermediaryCh)
+
+	for item := range intermediaryCh {
+		if item.Error != nil {
+			ch <- SourceItem{Error: item.Error}
+			continue
+		}
+
+		ch <- SourceItem{
+			EventInStream: item.EventInStream,
+			Cursor:        strconv.FormatInt(item.Position+1, 10),
+		}
+	}
+}
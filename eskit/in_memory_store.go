@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"github.com/dustin/go-broadcast"
 	"github.com/sroze/fossil/simplestore"
+	"strings"
 	"sync"
 )
 
+// InMemoryStore is a `simplestore.Store` keeping events in memory, for tests.
 type InMemoryStore struct {
 	byStream map[string][]simplestore.Event
+	starts   map[string]int64 // position of the streams' first event, which might not be 0.
 	ordered  []simplestore.EventInStream
 	b        broadcast.Broadcaster
 	mu       sync.Mutex
@@ -23,40 +26,74 @@ type appendNotification struct {
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
 		byStream: map[string][]simplestore.Event{},
+		starts:   map[string]int64{},
 		ordered:  []simplestore.EventInStream{},
 		b:        broadcast.NewBroadcaster(1),
 	}
 }
 
-func (s *InMemoryStore) Write(commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error) {
+// Write appends the events of all the commands, or none of them if one of the conditions fails.
+func (s *InMemoryStore) Write(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Checks all the conditions first, so that the write is atomic.
+	streamPositions := make(map[string]int64)
 	results := make([]simplestore.AppendResult, len(commands))
-
 	for i, command := range commands {
-		if _, ok := s.byStream[command.Stream]; !ok {
-			s.byStream[command.Stream] = []simplestore.Event{}
+		position, ok := streamPositions[command.Stream]
+		if !ok {
+			position = s.head(command.Stream)
+		}
+
+		if command.Condition != nil {
+			if command.Condition.WriteAtPosition < 0 {
+				return nil, fmt.Errorf("expected write position to be positive, got %d", command.Condition.WriteAtPosition)
+			}
+
+			// Empty streams can start at any position.
+			if command.Condition.StreamIsEmpty {
+				if position != -1 {
+					return nil, simplestore.StreamConditionFailed{
+						Stream:                 command.Stream,
+						ExpectedStreamPosition: -1,
+					}
+				}
+
+				position = command.Condition.WriteAtPosition - 1
+			} else if command.Condition.WriteAtPosition != position+1 {
+				return nil, simplestore.StreamConditionFailed{
+					Stream:                 command.Stream,
+					ExpectedStreamPosition: command.Condition.WriteAtPosition - 1,
+				}
+			}
 		}
 
-		positionOfFirstEvent := int64(len(s.byStream[command.Stream]) - 1)
-		if command.Condition != nil && command.Condition.WriteAtPosition != (positionOfFirstEvent+1) {
-			return nil, fmt.Errorf("expected position %d, but got %d", command.Condition.WriteAtPosition, positionOfFirstEvent)
+		streamPositions[command.Stream] = position + int64(len(command.Events))
+		results[i] = simplestore.AppendResult{
+			Position: streamPositions[command.Stream],
 		}
+	}
+
+	for i, command := range commands {
+		for j, event := range command.Events {
+			position := results[i].Position - int64(len(command.Events)-1-j)
+			if len(s.byStream[command.Stream]) == 0 {
+				s.starts[command.Stream] = position
+			}
 
-		for _, event := range command.Events {
 			s.byStream[command.Stream] = append(s.byStream[command.Stream], event)
 			s.ordered = append(s.ordered, simplestore.EventInStream{
 				Stream:   command.Stream,
-				Position: int64(len(s.byStream[command.Stream])),
+				Position: position,
 				Event:    event,
 			})
 		}
 
-		results[i] = simplestore.AppendResult{
-			Position: positionOfFirstEvent + int64(len(command.Events)),
-		}
-
 		s.b.Submit(appendNotification{
 			stream:   command.Stream,
 			position: results[i].Position,
@@ -66,35 +103,67 @@ func (s *InMemoryStore) Write(commands []simplestore.AppendToStream) ([]simplest
 	return results, nil
 }
 
+// Read sends the events of the stream. Forward reads which reach the end of the stream end
+// with an `EndOfStreamSignal`.
 func (s *InMemoryStore) Read(ctx context.Context, stream string, ch chan simplestore.ReadItem, options simplestore.ReadOptions) {
 	defer close(ch)
 	s.mu.Lock()
 
-	if len(s.byStream[stream]) < int(options.StartingPosition) {
-		s.mu.Unlock()
-		return
+	head := s.head(stream)
+	start := s.starts[stream]
+	if options.StartingPosition > start {
+		start = options.StartingPosition
 	}
 
-	eventsToBeSent := s.byStream[stream][options.StartingPosition:]
+	var eventsToBeSent []simplestore.Event
+	if start <= head {
+		eventsToBeSent = s.byStream[stream][start-s.starts[stream]:]
+	}
 	s.mu.Unlock()
 
-	for i, event := range eventsToBeSent {
-		ch <- simplestore.ReadItem{
-			EventInStream: &simplestore.EventInStream{
-				Stream:   stream,
-				Event:    event,
-				Position: options.StartingPosition + int64(i),
-			},
+	for i := range eventsToBeSent {
+		if options.Limit > 0 && i >= options.Limit {
+			return
+		}
+
+		// Backwards reads start from the head of the stream, down to the starting position.
+		index := i
+		if options.Backwards {
+			index = len(eventsToBeSent) - 1 - i
 		}
 
-		// Check if context is cancelled.
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			// continue!
+		case ch <- simplestore.ReadItem{
+			EventInStream: &simplestore.EventInStream{
+				Stream:   stream,
+				Event:    eventsToBeSent[index],
+				Position: start + int64(index),
+			},
+		}:
 		}
 	}
+
+	if options.Backwards || (options.Limit > 0 && len(eventsToBeSent) >= options.Limit) {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case ch <- simplestore.ReadItem{
+		EndOfStreamSignal: &simplestore.EndOfStreamSignal{StreamPosition: head},
+	}:
+	}
+}
+
+// head returns the position of the stream's last event, or `-1` if the stream is empty.
+func (s *InMemoryStore) head(stream string) int64 {
+	if len(s.byStream[stream]) == 0 {
+		return -1
+	}
+
+	return s.starts[stream] + int64(len(s.byStream[stream])-1)
 }
 
 func (s *InMemoryStore) Query(ctx context.Context, prefix string, startingPosition int64, ch chan simplestore.QueryItem) {
@@ -110,21 +179,18 @@ func (s *InMemoryStore) Query(ctx context.Context, prefix string, startingPositi
 	s.mu.Unlock()
 
 	for i, event := range eventsToBeSent {
-		if event.Stream[:len(prefix)] != prefix {
+		if !strings.HasPrefix(event.Stream, prefix) {
 			continue
 		}
 
-		ch <- simplestore.QueryItem{
-			EventInStream: &event,
-			Position:      int64(i),
-		}
-
-		// Check if context is cancelled.
+		event := event
 		select {
 		case <-ctx.Done():
 			return
-		default:
-			// continue!
+		case ch <- simplestore.QueryItem{
+			EventInStream: &event,
+			Position:      startingPosition + int64(i),
+		}:
 		}
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/simplestore"
+	simplestoreTesting "github.com/sroze/fossil/simplestore/testing"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -11,11 +12,15 @@ import (
 func Test_InMemoryStore(t *testing.T) {
 	s := NewInMemoryStore()
 
+	t.Run("acceptance", func(t *testing.T) {
+		simplestoreTesting.RunAcceptanceTest(t, s)
+	})
+
 	t.Run("write & read from a stream", func(t *testing.T) {
 		stream := "test" + uuid.NewString()
 
 		eventId := uuid.NewString()
-		result, err := s.Write([]simplestore.AppendToStream{
+		result, err := s.Write(context.Background(), []simplestore.AppendToStream{
 			{Stream: stream, Events: []simplestore.Event{
 				{EventId: eventId, EventType: "Foo", Payload: []byte("bar")},
 			}},
@@ -31,31 +36,33 @@ func Test_InMemoryStore(t *testing.T) {
 		assert.Equal(t, eventId, item.EventInStream.Event.EventId)
 
 		// Expect the end of stream signal.
-		item, more := <-ch
-		assert.False(t, more)
+		item = <-ch
 		assert.Nil(t, item.EventInStream)
 		assert.Nil(t, item.Error)
-		assert.Nil(t, item.EndOfStreamSignal)
+		assert.Equal(t, &simplestore.EndOfStreamSignal{StreamPosition: 0}, item.EndOfStreamSignal)
+
+		_, more := <-ch
+		assert.False(t, more)
 	})
 
 	t.Run("conflict on writes", func(t *testing.T) {
 		stream := "test" + uuid.NewString()
 
-		_, err := s.Write([]simplestore.AppendToStream{
+		_, err := s.Write(context.Background(), []simplestore.AppendToStream{
 			{Stream: stream, Events: []simplestore.Event{
 				{EventId: uuid.NewString(), EventType: "Foo", Payload: []byte("foo")},
 			}, Condition: &simplestore.AppendCondition{WriteAtPosition: 1}},
 		})
-		assert.NotNil(t, err)
+		assert.Equal(t, simplestore.StreamConditionFailed{Stream: stream, ExpectedStreamPosition: 0}, err)
 
-		_, err = s.Write([]simplestore.AppendToStream{
+		_, err = s.Write(context.Background(), []simplestore.AppendToStream{
 			{Stream: stream, Events: []simplestore.Event{
 				{EventId: uuid.NewString(), EventType: "Bar", Payload: []byte("bar")},
 			}, Condition: &simplestore.AppendCondition{WriteAtPosition: 0}},
 		})
 		assert.Nil(t, err)
 
-		_, err = s.Write([]simplestore.AppendToStream{
+		_, err = s.Write(context.Background(), []simplestore.AppendToStream{
 			{Stream: stream, Events: []simplestore.Event{
 				{EventId: uuid.NewString(), EventType: "Baz", Payload: []byte("baz")},
 			}, Condition: &simplestore.AppendCondition{WriteAtPosition: 1}},
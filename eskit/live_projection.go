@@ -2,15 +2,81 @@ package eskit
 
 import (
 	"context"
+	"fmt"
 	"github.com/sroze/fossil/eskit/codec"
 	"github.com/sroze/fossil/livetail"
 	"github.com/sroze/fossil/simplestore"
+	"sync"
+	"time"
 )
 
+type ErrorAction int
+
+const (
+	// StopOnError stops the projection at the first event that can't be applied.
+	StopOnError ErrorAction = iota
+
+	// SkipOnError skips the events that can't be applied and records them.
+	SkipOnError
+
+	// RetryOnError applies the event again, up to `MaxRetries` times, before stopping.
+	RetryOnError
+)
+
+// ErrorPolicy describes what a live projection does with the events it can't decode or apply.
+type ErrorPolicy struct {
+	Action     ErrorAction
+	MaxRetries int
+	RetryDelay time.Duration
+
+	// OnError, when set, is called with every error, including the ones that are skipped or retried.
+	OnError func(err ProjectionError)
+}
+
+// ProjectionError is the error that happened when applying the event at the given position.
+type ProjectionError struct {
+	Position int64
+	Err      error
+}
+
+func (e ProjectionError) Error() string {
+	return fmt.Sprintf("cannot apply event #%d: %s", e.Position, e.Err)
+}
+
+func (e ProjectionError) Unwrap() error {
+	return e.Err
+}
+
+type HealthStatus string
+
+const (
+	HealthRunning  HealthStatus = "running"
+	HealthCaughtUp HealthStatus = "caught-up"
+	HealthFailed   HealthStatus = "failed"
+)
+
+type Health struct {
+	Status HealthStatus
+
+	// The error that made the projection fail, when `Failed`.
+	Err error
+}
+
 type LiveProjection[T any] struct {
-	codec      codec.Codec
-	tail       *livetail.LiveTail
-	projection *Projection[T]
+	codec       codec.Codec
+	tail        *livetail.LiveTail
+	projection  *Projection[T]
+	errorPolicy ErrorPolicy
+
+	// Internal matters.
+	caughtUp     chan struct{}
+	caughtUpOnce sync.Once
+	done         chan struct{}
+	stopped      chan struct{}
+	stopOnce     sync.Once
+	healthMutex  sync.Mutex
+	health       Health
+	skipped      []ProjectionError
 }
 
 func NewLiveProjection[T any](
@@ -20,8 +86,12 @@ func NewLiveProjection[T any](
 	evolve func(T, interface{}) T,
 ) *LiveProjection[T] {
 	sp := LiveProjection[T]{
-		tail:  tail,
-		codec: codec,
+		tail:     tail,
+		codec:    codec,
+		caughtUp: make(chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		health:   Health{Status: HealthRunning},
 	}
 
 	sp.projection = NewProjection[T](
@@ -32,6 +102,12 @@ func NewLiveProjection[T any](
 	return &sp
 }
 
+// SetErrorPolicy configures how errors are handled. By default, the projection stops at the
+// first error.
+func (sp *LiveProjection[T]) SetErrorPolicy(policy ErrorPolicy) {
+	sp.errorPolicy = policy
+}
+
 func (sp *LiveProjection[T]) GetState() T {
 	return sp.projection.GetState()
 }
@@ -40,8 +116,42 @@ func (sp *LiveProjection[T]) GetPosition() int64 {
 	return sp.projection.GetPosition()
 }
 
-func (sp *LiveProjection[T]) WaitEndOfStream() {
-	sp.tail.WaitEndOfStream()
+// ReadState calls the function with the state and the position it corresponds to.
+func (sp *LiveProjection[T]) ReadState(fn func(state T, position int64)) {
+	sp.projection.ReadState(fn)
+}
+
+func (sp *LiveProjection[T]) Health() Health {
+	sp.healthMutex.Lock()
+	defer sp.healthMutex.Unlock()
+
+	return sp.health
+}
+
+// Skipped returns the errors of the events that were skipped.
+func (sp *LiveProjection[T]) Skipped() []ProjectionError {
+	sp.healthMutex.Lock()
+	defer sp.healthMutex.Unlock()
+
+	return append([]ProjectionError{}, sp.skipped...)
+}
+
+// WaitEndOfStream waits for the projection to have applied all the events of the stream. It
+// returns an error if the projection failed or was stopped before.
+func (sp *LiveProjection[T]) WaitEndOfStream() error {
+	select {
+	case <-sp.caughtUp:
+	case <-sp.done:
+	}
+
+	health := sp.Health()
+	if health.Status == HealthFailed {
+		return health.Err
+	} else if health.Status != HealthCaughtUp {
+		return livetail.ErrStoppedBeforeEndOfStream
+	}
+
+	return nil
 }
 
 func (sp *LiveProjection[T]) WaitForPosition(ctx context.Context, position int64) {
@@ -51,18 +161,27 @@ func (sp *LiveProjection[T]) WaitForPosition(ctx context.Context, position int64
 func (sp *LiveProjection[T]) Start() {
 	ch := make(chan simplestore.ReadItem)
 	go func() {
+		defer close(sp.done)
+
+		// The channel is drained even after a failure, so that the live tail can stop.
 		for item := range ch {
+			if sp.isStopped() || sp.Health().Status == HealthFailed {
+				continue
+			}
+
+			if item.Error != nil {
+				sp.fail(item.Error)
+			}
 
 			if item.EventInStream != nil {
-				deserialized, err := sp.codec.Deserialize(item.EventInStream.Event)
-				if err != nil {
-					panic(err)
-				}
+				sp.handle(*item.EventInStream)
+			}
 
-				err = sp.projection.Apply(deserialized, item.EventInStream.Position-1)
-				if err != nil {
-					panic(err)
-				}
+			if item.EndOfStreamSignal != nil {
+				sp.caughtUpOnce.Do(func() {
+					sp.setHealth(Health{Status: HealthCaughtUp})
+					close(sp.caughtUp)
+				})
 			}
 		}
 	}()
@@ -70,6 +189,88 @@ func (sp *LiveProjection[T]) Start() {
 	go sp.tail.Start("0", ch)
 }
 
+func (sp *LiveProjection[T]) handle(item simplestore.EventInStream) {
+	for retries := 0; ; retries++ {
+		err := sp.apply(item)
+		if err == nil {
+			return
+		}
+
+		projectionErr := ProjectionError{Position: item.Position, Err: err}
+		if sp.errorPolicy.OnError != nil {
+			sp.errorPolicy.OnError(projectionErr)
+		}
+
+		switch sp.errorPolicy.Action {
+		case SkipOnError:
+			err = sp.projection.skip(item.Position - 1)
+			if err != nil {
+				sp.fail(ProjectionError{Position: item.Position, Err: err})
+				return
+			}
+
+			sp.healthMutex.Lock()
+			sp.skipped = append(sp.skipped, projectionErr)
+			sp.healthMutex.Unlock()
+
+			return
+		case RetryOnError:
+			if retries < sp.errorPolicy.MaxRetries {
+				select {
+				case <-sp.stopped:
+					return
+				case <-time.After(sp.errorPolicy.RetryDelay):
+					continue
+				}
+			}
+		}
+
+		sp.fail(projectionErr)
+		return
+	}
+}
+
+// apply decodes and applies the event. Panics of the evolve function are returned as errors.
+func (sp *LiveProjection[T]) apply(item simplestore.EventInStream) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while applying event: %v", r)
+		}
+	}()
+
+	deserialized, err := sp.codec.Deserialize(item.Event)
+	if err != nil {
+		return err
+	}
+
+	return sp.projection.Apply(deserialized, item.Position-1)
+}
+
+func (sp *LiveProjection[T]) fail(err error) {
+	sp.setHealth(Health{Status: HealthFailed, Err: err})
+	sp.tail.Stop()
+}
+
+func (sp *LiveProjection[T]) setHealth(health Health) {
+	sp.healthMutex.Lock()
+	defer sp.healthMutex.Unlock()
+
+	sp.health = health
+}
+
+func (sp *LiveProjection[T]) isStopped() bool {
+	select {
+	case <-sp.stopped:
+		return true
+	default:
+		return false
+	}
+}
+
 func (sp *LiveProjection[T]) Stop() {
+	sp.stopOnce.Do(func() {
+		close(sp.stopped)
+	})
+
 	sp.tail.Stop()
 }
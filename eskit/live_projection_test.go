@@ -1,11 +1,14 @@
 package eskit
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/eskit/codec"
 	"github.com/sroze/fossil/livetail"
+	"github.com/sroze/fossil/simplestore"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_SubscribedProjection(t *testing.T) {
@@ -33,8 +36,102 @@ func Test_SubscribedProjection(t *testing.T) {
 
 		p.Start()
 		defer p.Stop()
-		p.WaitEndOfStream()
+		assert.Nil(t, p.WaitEndOfStream())
 
 		assert.Equal(t, stringAppendState("ab"), p.GetState())
 	})
 }
+
+func Test_LiveProjection_Errors(t *testing.T) {
+	c := codec.NewGobCodec(
+		appendEvent{},
+	)
+
+	// Writes "b", an event that can't be decoded and "c".
+	streamWithUndecodableEvent := func(ss *InMemoryStore) string {
+		stream := "foo/" + uuid.NewString()
+		rw := NewReaderWriter(ss, c)
+
+		_, err := rw.Write([]EventToWrite{{Stream: stream, Event: appendEvent{S: "b"}}})
+		assert.Nil(t, err)
+		_, err = ss.Write(context.Background(), []simplestore.AppendToStream{{
+			Stream: stream,
+			Events: []simplestore.Event{{EventId: uuid.NewString(), EventType: "Unknown"}},
+		}})
+		assert.Nil(t, err)
+		_, err = rw.Write([]EventToWrite{{Stream: stream, Event: appendEvent{S: "c"}}})
+		assert.Nil(t, err)
+
+		return stream
+	}
+
+	t.Run("stops at the first error by default", func(t *testing.T) {
+		ss := NewInMemoryStore()
+		stream := streamWithUndecodableEvent(ss)
+		p := NewLiveProjection(livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, "a", evolveStringAppend)
+
+		p.Start()
+		defer p.Stop()
+
+		err := p.WaitEndOfStream()
+		var projectionErr ProjectionError
+		assert.ErrorAs(t, err, &projectionErr)
+		assert.Equal(t, int64(1), projectionErr.Position)
+		assert.Equal(t, HealthFailed, p.Health().Status)
+		assert.Equal(t, stringAppendState("ab"), p.GetState())
+	})
+
+	t.Run("skips and records the events that can't be applied", func(t *testing.T) {
+		ss := NewInMemoryStore()
+		stream := streamWithUndecodableEvent(ss)
+		p := NewLiveProjection(livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, "a", evolveStringAppend)
+
+		var reported []ProjectionError
+		p.SetErrorPolicy(ErrorPolicy{
+			Action: SkipOnError,
+			OnError: func(err ProjectionError) {
+				reported = append(reported, err)
+			},
+		})
+
+		p.Start()
+		defer p.Stop()
+
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Equal(t, HealthCaughtUp, p.Health().Status)
+		assert.Equal(t, stringAppendState("abc"), p.GetState())
+		assert.Equal(t, int64(2), p.GetPosition())
+		assert.Equal(t, 1, len(p.Skipped()))
+		assert.Equal(t, int64(1), p.Skipped()[0].Position)
+		assert.Equal(t, p.Skipped(), reported)
+	})
+
+	t.Run("retries the events that can't be applied", func(t *testing.T) {
+		ss := NewInMemoryStore()
+		stream := "foo/" + uuid.NewString()
+		_, err := NewReaderWriter(ss, c).Write([]EventToWrite{{Stream: stream, Event: appendEvent{S: "b"}}})
+		assert.Nil(t, err)
+
+		attempts := 0
+		p := NewLiveProjection(livetail.NewLiveTail(livetail.NewStreamReader(ss, stream)), c, "a", func(state stringAppendState, event interface{}) stringAppendState {
+			attempts++
+			if attempts == 1 {
+				panic("transient failure")
+			}
+
+			return evolveStringAppend(state, event)
+		})
+		p.SetErrorPolicy(ErrorPolicy{
+			Action:     RetryOnError,
+			MaxRetries: 2,
+			RetryDelay: time.Millisecond,
+		})
+
+		p.Start()
+		defer p.Stop()
+
+		assert.Nil(t, p.WaitEndOfStream())
+		assert.Equal(t, 2, attempts)
+		assert.Equal(t, stringAppendState("ab"), p.GetState())
+	})
+}
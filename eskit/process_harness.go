@@ -0,0 +1,117 @@
+package eskit
+
+import (
+	"context"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"time"
+)
+
+// ProcessHarness runs a process manager against an `InMemoryStore`, for tests. Events are
+// handled synchronously, and wakeups are fired with a clock that only moves when told to.
+type ProcessHarness[S any] struct {
+	Store *InMemoryStore
+
+	rw      *ReaderWriter
+	manager *ProcessManager[S]
+	now     time.Time
+}
+
+func NewProcessHarness[S any](
+	process Process[S],
+	codec codec.Codec,
+) *ProcessHarness[S] {
+	store := NewInMemoryStore()
+	h := &ProcessHarness[S]{
+		Store: store,
+		rw:    NewReaderWriter(store, codec),
+		now:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	h.manager = NewProcessManager(process, store, NewSimpleStoreEventSource(store), codec, nil)
+	h.manager.SetClock(h.Now)
+
+	return h
+}
+
+// Now returns the time of the harness's clock.
+func (h *ProcessHarness[S]) Now() time.Time {
+	return h.now
+}
+
+// Publish writes the events to the stream, and runs the process manager.
+func (h *ProcessHarness[S]) Publish(ctx context.Context, stream string, events ...interface{}) error {
+	toWrite := make([]EventToWrite, len(events))
+	for i, event := range events {
+		toWrite[i] = EventToWrite{Stream: stream, Event: event}
+	}
+
+	_, err := h.rw.Write(toWrite)
+	if err != nil {
+		return err
+	}
+
+	return h.Run(ctx)
+}
+
+// Advance moves the clock forward, and runs the process manager.
+func (h *ProcessHarness[S]) Advance(ctx context.Context, d time.Duration) error {
+	h.now = h.now.Add(d)
+
+	return h.Run(ctx)
+}
+
+// Run handles the events that were not handled yet and fires the due wakeups, until the
+// processes have nothing left to do.
+func (h *ProcessHarness[S]) Run(ctx context.Context) error {
+	for {
+		// The messages sent by the processes might be in the streams they subscribe to.
+		cursor := h.manager.GetCheckpoint()
+		err := h.manager.catchUp(ctx)
+		if err != nil || h.manager.GetCheckpoint() != cursor {
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		fired, err := h.manager.fireDueWakeups(ctx)
+		if err != nil || fired == 0 {
+			return err
+		}
+	}
+}
+
+// State returns the state of the process identified by the correlation key.
+func (h *ProcessHarness[S]) State(ctx context.Context, correlationKey string) (S, error) {
+	instance, err := h.manager.load(ctx, correlationKey)
+	if err != nil {
+		return h.manager.process.InitialState, err
+	}
+
+	return instance.projection.GetState(), nil
+}
+
+// Events returns the decoded events of the stream, such as the messages sent by the processes.
+// The wakeups recorded in the streams of the processes are left out.
+func (h *ProcessHarness[S]) Events(ctx context.Context, stream string) ([]interface{}, error) {
+	ch := make(chan simplestore.ReadItem)
+	go h.Store.Read(ctx, stream, ch, simplestore.ReadOptions{})
+
+	var events []interface{}
+	var err error
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil && !isProcessManagerEvent(item.EventInStream.Event) {
+			var event interface{}
+			event, err = h.rw.codec.Deserialize(item.EventInStream.Event)
+			events = append(events, event)
+		}
+	}
+
+	return events, err
+}
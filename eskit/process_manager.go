@@ -0,0 +1,615 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	causationIdMetadata = "causation-id"
+	wakeupNameMetadata  = "wakeup-name"
+	wakeupAtMetadata    = "wakeup-at"
+
+	// Events recorded in the streams of the processes, next to their own events.
+	handledEventType         = "$handled"
+	wakeupScheduledEventType = "$wakeup-scheduled"
+	wakeupFiredEventType     = "$wakeup-fired"
+)
+
+// ProcessPollInterval is the time a process manager waits, once it handled all the available
+// events, before querying the new ones and firing the due wakeups.
+var ProcessPollInterval = 100 * time.Millisecond
+
+// StreamStore reads and writes streams. Both `simplestore.Store` and `store.Store` implement it.
+type StreamStore interface {
+	Write(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error)
+	Read(ctx context.Context, stream string, ch chan simplestore.ReadItem, options simplestore.ReadOptions)
+}
+
+// Wakeup is delivered to a process once its time has come. Scheduling a wakeup replaces the
+// pending one of the process with the same name, so that timeouts can be pushed back.
+type Wakeup struct {
+	Name string
+	At   time.Time
+}
+
+// ProcessDecision is what a process does when handling an event or a wakeup. All of it is
+// written atomically, in the same batch.
+type ProcessDecision struct {
+	// Events are appended to the process's stream, and evolve its state.
+	Events []interface{}
+
+	// Messages are the commands or events sent to other streams.
+	Messages []EventToWrite
+
+	// Wakeups are scheduled for the process.
+	Wakeups []Wakeup
+}
+
+// Process describes a workflow spanning multiple streams. Each of its instances, identified by
+// a correlation key, keeps its state in its own stream (see `ProcessStreamFor`).
+type Process[S any] struct {
+	// Name identifies the process manager's checkpoint and the streams of its processes.
+	Name string
+
+	// Prefix of the streams which events are handled.
+	Prefix string
+
+	InitialState S
+	Evolve       EvolveFunc[S]
+
+	// Correlate returns the key of the process the event belongs to, or an empty string if
+	// the event is not relevant.
+	Correlate func(stream string, event interface{}) string
+
+	// Handle decides what the process does with the event, which is either a decoded event
+	// of the subscribed streams or a `Wakeup`.
+	Handle func(state S, event interface{}) (ProcessDecision, error)
+}
+
+// ProcessStreamFor returns the name of the stream in which the process identified by the
+// correlation key is stored.
+func ProcessStreamFor(name string, correlationKey string) string {
+	return processStreamPrefix(name) + correlationKey
+}
+
+func processStreamPrefix(name string) string {
+	return "$process-" + name + "/"
+}
+
+// ProcessManager feeds the processes with the events of the streams matching the process's
+// prefix, and with their wakeups. The query's cursor is checkpointed like the one of a
+// `CheckpointedProjection`. Events delivered again after a restart are recognised from the
+// process's stream and ignored.
+type ProcessManager[S any] struct {
+	process     Process[S]
+	store       StreamStore
+	source      EventSource
+	codec       codec.Codec
+	checkpoints CheckpointStore
+	frequency   int
+	now         func() time.Time
+
+	ctx       context.Context
+	ctxCancel context.CancelFunc
+	done      chan struct{}
+
+	// The pending wakeups, per correlation key. Only used by the routine handling the events.
+	wakeups map[string]map[string]time.Time
+
+	// Guarded by `mutex`.
+	mutex                sync.Mutex
+	cursor               string
+	savedCursor          string
+	eventsSinceLastSaved int
+	err                  error
+}
+
+// processInstance is the state of a process, as loaded from its stream.
+type processInstance[S any] struct {
+	projection *Projection[S]
+
+	// The events and wakeups that were handled, by causation id.
+	handled map[string]bool
+	wakeups map[string]time.Time
+}
+
+func NewProcessManager[S any](
+	process Process[S],
+	store StreamStore,
+	source EventSource,
+	codec codec.Codec,
+	checkpoints CheckpointStore,
+) *ProcessManager[S] {
+	return &ProcessManager[S]{
+		process:     process,
+		store:       store,
+		source:      source,
+		codec:       codec,
+		checkpoints: checkpoints,
+		frequency:   DefaultCheckpointFrequency,
+		now:         time.Now,
+		wakeups:     map[string]map[string]time.Time{},
+	}
+}
+
+// SetCheckpointFrequency sets the number of events handled between two checkpoints.
+func (pm *ProcessManager[S]) SetCheckpointFrequency(frequency int) {
+	pm.frequency = frequency
+}
+
+// SetClock replaces the function used to know whether wakeups are due.
+func (pm *ProcessManager[S]) SetClock(now func() time.Time) {
+	pm.now = now
+}
+
+// Start loads the checkpoint and the pending wakeups, and starts handling events.
+func (pm *ProcessManager[S]) Start(ctx context.Context) error {
+	cursor, err := pm.checkpoints.Load(ctx, pm.process.Name)
+	if err != nil {
+		return fmt.Errorf("cannot load checkpoint of %s: %w", pm.process.Name, err)
+	}
+
+	err = pm.loadWakeups(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load wakeups of %s: %w", pm.process.Name, err)
+	}
+
+	pm.cursor = cursor
+	pm.savedCursor = cursor
+	pm.ctx, pm.ctxCancel = context.WithCancel(context.Background())
+	pm.done = make(chan struct{})
+
+	go pm.run()
+
+	return nil
+}
+
+func (pm *ProcessManager[S]) run() {
+	defer close(pm.done)
+
+	for {
+		err := pm.catchUp(pm.ctx)
+		if err == nil {
+			err = pm.saveCheckpoint(pm.ctx)
+		}
+		if err == nil {
+			_, err = pm.fireDueWakeups(pm.ctx)
+		}
+
+		if err != nil {
+			// Errors caused by the process manager being stopped are not failures.
+			if pm.ctx.Err() == nil {
+				pm.mutex.Lock()
+				pm.err = err
+				pm.mutex.Unlock()
+			}
+
+			return
+		}
+
+		select {
+		case <-pm.ctx.Done():
+			return
+		case <-time.After(ProcessPollInterval):
+		}
+	}
+}
+
+// loadWakeups rebuilds the pending wakeups from the streams of the processes.
+func (pm *ProcessManager[S]) loadWakeups(ctx context.Context) error {
+	ch := make(chan SourceItem)
+	go pm.source.Query(ctx, processStreamPrefix(pm.process.Name), "", ch)
+
+	var err error
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+			continue
+		}
+
+		correlationKey := strings.TrimPrefix(item.EventInStream.Stream, processStreamPrefix(pm.process.Name))
+		wakeups, ok := pm.wakeups[correlationKey]
+		if !ok {
+			wakeups = map[string]time.Time{}
+			pm.wakeups[correlationKey] = wakeups
+		}
+
+		err = applyWakeupEvent(wakeups, item.EventInStream.Event)
+	}
+
+	return err
+}
+
+// catchUp handles the events written after the cursor.
+func (pm *ProcessManager[S]) catchUp(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan SourceItem)
+	go pm.source.Query(ctx, pm.process.Prefix, pm.GetCheckpoint(), ch)
+
+	// The channel is drained even after a failure, so that the query can stop.
+	var err error
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+		} else {
+			err = pm.handle(ctx, *item.EventInStream)
+			if err == nil {
+				err = pm.advance(ctx, item.Cursor)
+			}
+		}
+
+		if err != nil {
+			cancel()
+		}
+	}
+
+	return err
+}
+
+func (pm *ProcessManager[S]) advance(ctx context.Context, cursor string) error {
+	pm.mutex.Lock()
+	pm.cursor = cursor
+	pm.eventsSinceLastSaved++
+	shouldSave := pm.eventsSinceLastSaved >= pm.frequency
+	pm.mutex.Unlock()
+
+	if shouldSave {
+		return pm.saveCheckpoint(ctx)
+	}
+
+	return nil
+}
+
+func (pm *ProcessManager[S]) saveCheckpoint(ctx context.Context) error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	if pm.cursor == pm.savedCursor {
+		return nil
+	}
+
+	err := pm.checkpoints.Save(ctx, pm.process.Name, pm.cursor)
+	if err != nil {
+		return fmt.Errorf("cannot save checkpoint of %s: %w", pm.process.Name, err)
+	}
+
+	pm.savedCursor = pm.cursor
+	pm.eventsSinceLastSaved = 0
+
+	return nil
+}
+
+func (pm *ProcessManager[S]) handle(ctx context.Context, item simplestore.EventInStream) error {
+	// The process's own streams might match the prefix.
+	if strings.HasPrefix(item.Stream, processStreamPrefix(pm.process.Name)) {
+		return nil
+	}
+
+	event, err := pm.codec.Deserialize(item.Event)
+	if err != nil {
+		return fmt.Errorf("cannot decode event #%d of %s: %w", item.Position, item.Stream, err)
+	}
+
+	correlationKey := pm.process.Correlate(item.Stream, event)
+	if correlationKey == "" {
+		return nil
+	}
+
+	return pm.transition(ctx, correlationKey, item.Event.EventId, event, nil)
+}
+
+// fireDueWakeups delivers the wakeups which time has come, in chronological order. It returns
+// the number of wakeups that were due.
+func (pm *ProcessManager[S]) fireDueWakeups(ctx context.Context) (int, error) {
+	type dueWakeup struct {
+		correlationKey string
+		wakeup         Wakeup
+	}
+
+	now := pm.now()
+	var due []dueWakeup
+	for correlationKey, wakeups := range pm.wakeups {
+		for name, at := range wakeups {
+			if !at.After(now) {
+				due = append(due, dueWakeup{correlationKey, Wakeup{Name: name, At: at}})
+			}
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].wakeup.At.Before(due[j].wakeup.At)
+	})
+
+	for _, d := range due {
+		wakeup := d.wakeup
+		err := pm.transition(ctx, d.correlationKey, wakeupCausationId(wakeup), wakeup, &wakeup)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(due), nil
+}
+
+// transition loads the process, lets it handle the event and writes its decision, expecting
+// the process's stream to still be at the loaded position. If it was written in the meantime,
+// the event is handled again, up to `AggregateMaxRetries` times.
+func (pm *ProcessManager[S]) transition(ctx context.Context, correlationKey string, causationId string, event interface{}, firedWakeup *Wakeup) error {
+	for retries := 0; ; retries++ {
+		instance, err := pm.load(ctx, correlationKey)
+		if err != nil {
+			return err
+		}
+
+		if instance.handled[causationId] {
+			pm.wakeups[correlationKey] = instance.wakeups
+			return nil
+		}
+
+		if firedWakeup != nil {
+			at, ok := instance.wakeups[firedWakeup.Name]
+			if !ok || !at.Equal(firedWakeup.At) {
+				// The wakeup was re-scheduled in the meantime.
+				pm.wakeups[correlationKey] = instance.wakeups
+				return nil
+			}
+		}
+
+		decision, err := pm.process.Handle(instance.projection.GetState(), event)
+		if err != nil {
+			return fmt.Errorf("process %s failed to handle %s: %w", correlationKey, causationId, err)
+		}
+
+		commands, err := pm.commandsFor(correlationKey, causationId, instance, decision, firedWakeup)
+		if err != nil {
+			return err
+		}
+
+		if len(commands) > 0 {
+			_, err = pm.store.Write(ctx, commands)
+		}
+
+		if err == nil {
+			wakeups := instance.wakeups
+			if firedWakeup != nil {
+				delete(wakeups, firedWakeup.Name)
+			}
+			for _, wakeup := range decision.Wakeups {
+				wakeups[wakeup.Name] = wakeup.At
+			}
+
+			pm.wakeups[correlationKey] = wakeups
+			return nil
+		}
+
+		var conditionFailed simplestore.StreamConditionFailed
+		if !errors.As(err, &conditionFailed) || retries >= AggregateMaxRetries {
+			return err
+		}
+	}
+}
+
+func (pm *ProcessManager[S]) commandsFor(correlationKey string, causationId string, instance *processInstance[S], decision ProcessDecision, firedWakeup *Wakeup) ([]simplestore.AppendToStream, error) {
+	var events []simplestore.Event
+	for _, event := range decision.Events {
+		serializedEvent, err := pm.codec.Serialize(event)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, serializedEvent)
+	}
+
+	for _, wakeup := range decision.Wakeups {
+		events = append(events, wakeupEvent(wakeupScheduledEventType, wakeup))
+	}
+
+	if firedWakeup != nil {
+		events = append(events, wakeupEvent(wakeupFiredEventType, *firedWakeup))
+	}
+
+	// The messages are sent along with an event of the process, so that they are not sent again
+	// if the event is delivered again.
+	if len(events) == 0 && len(decision.Messages) > 0 {
+		events = append(events, simplestore.Event{
+			EventId:   uuid.NewString(),
+			EventType: handledEventType,
+		})
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	commands := []simplestore.AppendToStream{
+		{
+			Stream: ProcessStreamFor(pm.process.Name, correlationKey),
+			Events: withCausationId(events, causationId),
+			Condition: &simplestore.AppendCondition{
+				WriteAtPosition: instance.projection.GetPosition() + 1,
+			},
+		},
+	}
+
+	for _, message := range decision.Messages {
+		serializedEvent, err := pm.codec.Serialize(message.Event)
+		if err != nil {
+			return nil, err
+		}
+
+		command := simplestore.AppendToStream{
+			Stream: message.Stream,
+			Events: withCausationId([]simplestore.Event{serializedEvent}, causationId),
+		}
+
+		if message.ExpectedPosition != nil {
+			command.Condition = &simplestore.AppendCondition{
+				WriteAtPosition: *message.ExpectedPosition + 1,
+			}
+		}
+
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
+
+// load reads the stream of the process.
+func (pm *ProcessManager[S]) load(ctx context.Context, correlationKey string) (*processInstance[S], error) {
+	instance := &processInstance[S]{
+		projection: NewProjection(pm.process.InitialState, pm.process.Evolve),
+		handled:    map[string]bool{},
+		wakeups:    map[string]time.Time{},
+	}
+
+	ch := make(chan simplestore.ReadItem)
+	go pm.store.Read(ctx, ProcessStreamFor(pm.process.Name, correlationKey), ch, simplestore.ReadOptions{})
+
+	var err error
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil {
+			err = pm.apply(instance, *item.EventInStream)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return instance, ctx.Err()
+}
+
+func (pm *ProcessManager[S]) apply(instance *processInstance[S], item simplestore.EventInStream) error {
+	if causationId, ok := item.Event.Metadata[causationIdMetadata]; ok {
+		instance.handled[causationId] = true
+	}
+
+	if isProcessManagerEvent(item.Event) {
+		err := applyWakeupEvent(instance.wakeups, item.Event)
+		if err != nil {
+			return err
+		}
+
+		return instance.projection.skip(item.Position - 1)
+	}
+
+	event, err := pm.codec.Deserialize(item.Event)
+	if err != nil {
+		return err
+	}
+
+	return instance.projection.Apply(event, item.Position-1)
+}
+
+// isProcessManagerEvent returns true for the events recorded by the process manager in the
+// streams of the processes, as opposed to the events of the processes.
+func isProcessManagerEvent(event simplestore.Event) bool {
+	switch event.EventType {
+	case handledEventType, wakeupScheduledEventType, wakeupFiredEventType:
+		return true
+	}
+
+	return false
+}
+
+// applyWakeupEvent updates the pending wakeups with the event, if it is about a wakeup.
+func applyWakeupEvent(wakeups map[string]time.Time, event simplestore.Event) error {
+	if event.EventType != wakeupScheduledEventType && event.EventType != wakeupFiredEventType {
+		return nil
+	}
+
+	name := event.Metadata[wakeupNameMetadata]
+	at, err := time.Parse(time.RFC3339Nano, event.Metadata[wakeupAtMetadata])
+	if err != nil {
+		return fmt.Errorf("invalid wakeup time: %w", err)
+	}
+
+	if event.EventType == wakeupScheduledEventType {
+		wakeups[name] = at
+	} else if pending, ok := wakeups[name]; ok && pending.Equal(at) {
+		delete(wakeups, name)
+	}
+
+	return nil
+}
+
+func wakeupEvent(eventType string, wakeup Wakeup) simplestore.Event {
+	return simplestore.Event{
+		EventId:   uuid.NewString(),
+		EventType: eventType,
+		Metadata: map[string]string{
+			wakeupNameMetadata: wakeup.Name,
+			wakeupAtMetadata:   wakeup.At.Format(time.RFC3339Nano),
+		},
+	}
+}
+
+func wakeupCausationId(wakeup Wakeup) string {
+	return "$wakeup-" + wakeup.Name + "@" + wakeup.At.Format(time.RFC3339Nano)
+}
+
+func withCausationId(events []simplestore.Event, causationId string) []simplestore.Event {
+	for i, event := range events {
+		metadata := map[string]string{causationIdMetadata: causationId}
+		for k, v := range event.Metadata {
+			metadata[k] = v
+		}
+
+		events[i].Metadata = metadata
+	}
+
+	return events
+}
+
+// GetCheckpoint returns the cursor from which the process manager would resume.
+func (pm *ProcessManager[S]) GetCheckpoint() string {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	return pm.cursor
+}
+
+// Err returns the error that stopped the process manager, if any.
+func (pm *ProcessManager[S]) Err() error {
+	pm.mutex.Lock()
+	defer pm.mutex.Unlock()
+
+	return pm.err
+}
+
+// Stop stops handling events and saves the checkpoint of the last handled one. It returns the
+// error that stopped the process manager, if any.
+func (pm *ProcessManager[S]) Stop() error {
+	if pm.done == nil {
+		return nil
+	}
+
+	pm.ctxCancel()
+	<-pm.done
+
+	saveErr := pm.saveCheckpoint(context.Background())
+	if err := pm.Err(); err != nil {
+		return err
+	}
+
+	return saveErr
+}
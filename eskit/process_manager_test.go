@@ -0,0 +1,272 @@
+package eskit
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type orderPlaced struct{ OrderId string }
+type paymentReceived struct{ OrderId string }
+type requestPayment struct{ OrderId string }
+type shipOrder struct{ OrderId string }
+type cancelOrder struct{ OrderId string }
+
+type orderFulfilmentStarted struct{}
+type orderFulfilmentPaid struct{}
+type orderFulfilmentCancelled struct{}
+
+type orderFulfilmentState struct {
+	Started   bool
+	Paid      bool
+	Cancelled bool
+}
+
+var orderCodec = codec.NewGobCodec(
+	orderPlaced{}, paymentReceived{}, requestPayment{}, shipOrder{}, cancelOrder{},
+	orderFulfilmentStarted{}, orderFulfilmentPaid{}, orderFulfilmentCancelled{},
+)
+
+// orderFulfilment requests the payment of placed orders, ships them once paid, and cancels
+// them if they are not paid within an hour.
+func orderFulfilment(now func() time.Time) Process[orderFulfilmentState] {
+	return Process[orderFulfilmentState]{
+		Name:         "order-fulfilment",
+		Prefix:       "orders/",
+		InitialState: orderFulfilmentState{},
+		Evolve: func(state orderFulfilmentState, event interface{}) orderFulfilmentState {
+			switch event.(type) {
+			case *orderFulfilmentStarted:
+				state.Started = true
+			case *orderFulfilmentPaid:
+				state.Paid = true
+			case *orderFulfilmentCancelled:
+				state.Cancelled = true
+			}
+
+			return state
+		},
+		Correlate: func(stream string, event interface{}) string {
+			switch e := event.(type) {
+			case *orderPlaced:
+				return e.OrderId
+			case *paymentReceived:
+				return e.OrderId
+			}
+
+			return ""
+		},
+		Handle: func(state orderFulfilmentState, event interface{}) (ProcessDecision, error) {
+			switch e := event.(type) {
+			case *orderPlaced:
+				return ProcessDecision{
+					Events:   []interface{}{orderFulfilmentStarted{}},
+					Messages: []EventToWrite{{Stream: "payments/" + e.OrderId, Event: requestPayment{OrderId: e.OrderId}}},
+					Wakeups:  []Wakeup{{Name: "payment-timeout", At: now().Add(time.Hour)}},
+				}, nil
+			case *paymentReceived:
+				if state.Cancelled {
+					return ProcessDecision{}, nil
+				}
+
+				return ProcessDecision{
+					Events:   []interface{}{orderFulfilmentPaid{}},
+					Messages: []EventToWrite{{Stream: "shipping/" + e.OrderId, Event: shipOrder{OrderId: e.OrderId}}},
+				}, nil
+			case Wakeup:
+				if state.Paid {
+					return ProcessDecision{}, nil
+				}
+
+				return ProcessDecision{
+					Events: []interface{}{orderFulfilmentCancelled{}},
+				}, nil
+			}
+
+			return ProcessDecision{}, nil
+		},
+	}
+}
+
+func Test_ProcessManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("correlates events and sends messages along with the process's events", func(t *testing.T) {
+		var h *ProcessHarness[orderFulfilmentState]
+		h = NewProcessHarness(orderFulfilment(func() time.Time { return h.Now() }), orderCodec)
+		orderId := uuid.NewString()
+
+		assert.Nil(t, h.Publish(ctx, "orders/"+orderId, orderPlaced{OrderId: orderId}))
+		state, err := h.State(ctx, orderId)
+		assert.Nil(t, err)
+		assert.Equal(t, orderFulfilmentState{Started: true}, state)
+
+		messages, err := h.Events(ctx, "payments/"+orderId)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{&requestPayment{OrderId: orderId}}, messages)
+
+		assert.Nil(t, h.Publish(ctx, "orders/"+orderId+"/payments", paymentReceived{OrderId: orderId}))
+		state, err = h.State(ctx, orderId)
+		assert.Nil(t, err)
+		assert.Equal(t, orderFulfilmentState{Started: true, Paid: true}, state)
+
+		messages, err = h.Events(ctx, "shipping/"+orderId)
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{&shipOrder{OrderId: orderId}}, messages)
+	})
+
+	t.Run("fires the wakeups once their time has come", func(t *testing.T) {
+		var h *ProcessHarness[orderFulfilmentState]
+		h = NewProcessHarness(orderFulfilment(func() time.Time { return h.Now() }), orderCodec)
+		unpaidOrderId := uuid.NewString()
+		paidOrderId := uuid.NewString()
+
+		assert.Nil(t, h.Publish(ctx, "orders/"+unpaidOrderId, orderPlaced{OrderId: unpaidOrderId}))
+		assert.Nil(t, h.Publish(ctx, "orders/"+paidOrderId, orderPlaced{OrderId: paidOrderId}, paymentReceived{OrderId: paidOrderId}))
+
+		assert.Nil(t, h.Advance(ctx, 59*time.Minute))
+		state, err := h.State(ctx, unpaidOrderId)
+		assert.Nil(t, err)
+		assert.False(t, state.Cancelled)
+
+		assert.Nil(t, h.Advance(ctx, time.Minute))
+		state, err = h.State(ctx, unpaidOrderId)
+		assert.Nil(t, err)
+		assert.True(t, state.Cancelled)
+
+		state, err = h.State(ctx, paidOrderId)
+		assert.Nil(t, err)
+		assert.Equal(t, orderFulfilmentState{Started: true, Paid: true}, state)
+
+		// Wakeups fire only once.
+		assert.Nil(t, h.Advance(ctx, time.Hour))
+		events, err := h.Events(ctx, ProcessStreamFor("order-fulfilment", unpaidOrderId))
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{&orderFulfilmentStarted{}, &orderFulfilmentCancelled{}}, events)
+	})
+
+	t.Run("ignores events delivered again", func(t *testing.T) {
+		var h *ProcessHarness[orderFulfilmentState]
+		h = NewProcessHarness(orderFulfilment(func() time.Time { return h.Now() }), orderCodec)
+		orderId := uuid.NewString()
+
+		assert.Nil(t, h.Publish(ctx, "orders/"+orderId, orderPlaced{OrderId: orderId}))
+
+		// Resuming from the beginning, like after a restart without checkpoint.
+		h.manager.cursor = ""
+		assert.Nil(t, h.Run(ctx))
+
+		messages, err := h.Events(ctx, "payments/"+orderId)
+		assert.Nil(t, err)
+		assert.Len(t, messages, 1)
+	})
+
+	t.Run("sends the messages expecting an empty stream only once", func(t *testing.T) {
+		process := orderFulfilment(time.Now)
+		process.Handle = func(state orderFulfilmentState, event interface{}) (ProcessDecision, error) {
+			e := event.(*orderPlaced)
+			expectedPosition := int64(-1)
+
+			return ProcessDecision{
+				Events:   []interface{}{orderFulfilmentStarted{}},
+				Messages: []EventToWrite{{Stream: "payments/" + e.OrderId, Event: requestPayment{OrderId: e.OrderId}, ExpectedPosition: &expectedPosition}},
+			}, nil
+		}
+
+		h := NewProcessHarness(process, orderCodec)
+		orderId := uuid.NewString()
+
+		_, err := h.rw.Write([]EventToWrite{{Stream: "payments/" + orderId, Event: requestPayment{OrderId: orderId}}})
+		assert.Nil(t, err)
+
+		var conditionFailed simplestore.StreamConditionFailed
+		assert.ErrorAs(t, h.Publish(ctx, "orders/"+orderId, orderPlaced{OrderId: orderId}), &conditionFailed)
+
+		messages, err := h.Events(ctx, "payments/"+orderId)
+		assert.Nil(t, err)
+		assert.Len(t, messages, 1)
+	})
+
+	t.Run("resumes from its checkpoint and reloads the pending wakeups", func(t *testing.T) {
+		store := NewInMemoryStore()
+		checkpoints := NewFileCheckpointStore(t.TempDir())
+		rw := NewReaderWriter(store, orderCodec)
+
+		now := time.Now()
+		clock := func() time.Time { return now }
+		newManager := func() *ProcessManager[orderFulfilmentState] {
+			pm := NewProcessManager(orderFulfilment(time.Now), store, NewSimpleStoreEventSource(store), orderCodec, checkpoints)
+			pm.SetClock(clock)
+
+			return pm
+		}
+
+		orderId := uuid.NewString()
+		_, err := rw.Write([]EventToWrite{{Stream: "orders/" + orderId, Event: orderPlaced{OrderId: orderId}}})
+		assert.Nil(t, err)
+
+		pm := newManager()
+		assert.Nil(t, pm.Start(ctx))
+		assert.Eventually(t, func() bool {
+			return pm.GetCheckpoint() != ""
+		}, time.Second, 10*time.Millisecond)
+		assert.Nil(t, pm.Stop())
+
+		checkpoint, err := checkpoints.Load(ctx, "order-fulfilment")
+		assert.Nil(t, err)
+		assert.Equal(t, pm.GetCheckpoint(), checkpoint)
+
+		now = now.Add(2 * time.Hour)
+		pm = newManager()
+		assert.Nil(t, pm.Start(ctx))
+		defer pm.Stop()
+
+		assert.Eventually(t, func() bool {
+			instance, err := pm.load(ctx, orderId)
+			return err == nil && instance.projection.GetState().Cancelled
+		}, time.Second, 10*time.Millisecond)
+
+		assert.Len(t, readStreamEvents(t, rw, "payments/"+orderId), 1)
+	})
+
+	t.Run("stops when a process fails", func(t *testing.T) {
+		store := NewInMemoryStore()
+		rw := NewReaderWriter(store, orderCodec)
+		process := orderFulfilment(time.Now)
+		process.Handle = func(state orderFulfilmentState, event interface{}) (ProcessDecision, error) {
+			return ProcessDecision{}, assert.AnError
+		}
+
+		orderId := uuid.NewString()
+		_, err := rw.Write([]EventToWrite{{Stream: "orders/" + orderId, Event: orderPlaced{OrderId: orderId}}})
+		assert.Nil(t, err)
+
+		pm := NewProcessManager(process, store, NewSimpleStoreEventSource(store), orderCodec, NewFileCheckpointStore(t.TempDir()))
+		assert.Nil(t, pm.Start(ctx))
+		assert.Eventually(t, func() bool {
+			return pm.Err() != nil
+		}, time.Second, 10*time.Millisecond)
+
+		assert.ErrorIs(t, pm.Stop(), assert.AnError)
+		assert.Equal(t, "", pm.GetCheckpoint())
+	})
+}
+
+func readStreamEvents(t *testing.T, rw *ReaderWriter, stream string) []interface{} {
+	ch := make(chan ReadItem)
+	go rw.Read(context.Background(), stream, 0, ch)
+
+	var events []interface{}
+	for item := range ch {
+		assert.Nil(t, item.Error)
+		if item.EventInStream != nil {
+			events = append(events, item.EventInStream.Event)
+		}
+	}
+
+	return events
+}
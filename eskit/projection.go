@@ -3,21 +3,27 @@ package eskit
 import (
 	"context"
 	"fmt"
-	"github.com/dustin/go-broadcast"
 	"sync"
 )
 
+// EvolveFunc returns the state once the event is applied. The given state might be read
+// concurrently, so it must not be mutated: return a modified copy instead.
 type EvolveFunc[T any] func(state T, event interface{}) T
 
 type Projection[T any] struct {
 	// Provided by the user.
 	evolve EvolveFunc[T]
 
-	// Internal matters.
-	state            T
-	position         int64
-	eventBroadcaster broadcast.Broadcaster
-	mutex            sync.Mutex
+	// Internal matters, guarded by `mutex`.
+	state    T
+	position int64
+	waiters  []positionWaiter
+	mutex    sync.RWMutex
+}
+
+type positionWaiter struct {
+	position int64
+	ch       chan struct{}
 }
 
 func NewProjection[T any](
@@ -25,13 +31,25 @@ func NewProjection[T any](
 	evolveFunc EvolveFunc[T],
 ) *Projection[T] {
 	return &Projection[T]{
-		position:         -1,
-		state:            initialState,
-		evolve:           evolveFunc,
-		eventBroadcaster: broadcast.NewBroadcaster(1),
+		position: -1,
+		state:    initialState,
+		evolve:   evolveFunc,
 	}
 }
 
+// newProjectionAt returns a projection which state is the one once the event at `position`
+// was applied.
+func newProjectionAt[T any](
+	state T,
+	position int64,
+	evolveFunc EvolveFunc[T],
+) *Projection[T] {
+	a := NewProjection(state, evolveFunc)
+	a.position = position
+
+	return a
+}
+
 func NewProjectionFromEvents[T any](
 	initialState T,
 	evolveFunc EvolveFunc[T],
@@ -58,40 +76,88 @@ func (a *Projection[T]) Apply(event interface{}, expectedStreamPosition int64) e
 	}
 
 	a.state = a.evolve(a.state, event)
-	a.position = a.position + 1
-	a.eventBroadcaster.Submit(a.position)
+	a.advance()
 
 	return nil
 }
 
-func (a *Projection[T]) WaitForPosition(ctx context.Context, position int64) {
-	ch := make(chan interface{})
+// skip moves the projection past the event at `expectedStreamPosition + 1`, without applying it.
+func (a *Projection[T]) skip(expectedStreamPosition int64) error {
 	a.mutex.Lock()
-	a.eventBroadcaster.Register(ch)
-	defer a.eventBroadcaster.Unregister(ch)
+	defer a.mutex.Unlock()
+
+	if a.position != expectedStreamPosition {
+		return fmt.Errorf("expected position %d, but got %d", a.position, expectedStreamPosition)
+	}
+
+	a.advance()
+
+	return nil
+}
+
+// advance moves the projection to the next position and releases the routines waiting for it,
+// in the order of the positions. The caller must hold the mutex.
+func (a *Projection[T]) advance() {
+	a.position = a.position + 1
+
+	var waiting []positionWaiter
+	for _, w := range a.waiters {
+		if w.position <= a.position {
+			close(w.ch)
+		} else {
+			waiting = append(waiting, w)
+		}
+	}
+
+	a.waiters = waiting
+}
 
+// WaitForPosition waits for the event at `position` to be applied, or for the context to be done.
+func (a *Projection[T]) WaitForPosition(ctx context.Context, position int64) {
+	a.mutex.Lock()
 	if a.position >= position {
+		a.mutex.Unlock()
 		return
 	}
 
+	w := positionWaiter{position: position, ch: make(chan struct{})}
+	a.waiters = append(a.waiters, w)
 	a.mutex.Unlock()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case p := <-ch:
-			if p.(int64) >= position {
-				return
+	select {
+	case <-w.ch:
+	case <-ctx.Done():
+		a.mutex.Lock()
+		defer a.mutex.Unlock()
+
+		for i, other := range a.waiters {
+			if other.ch == w.ch {
+				a.waiters = append(a.waiters[:i], a.waiters[i+1:]...)
+				break
 			}
 		}
 	}
 }
 
+// ReadState calls the function with the state and the position it corresponds to. Events are
+// not applied until the function returns.
+func (a *Projection[T]) ReadState(fn func(state T, position int64)) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	fn(a.state, a.position)
+}
+
 func (a *Projection[T]) GetState() T {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
 	return a.state
 }
 
 func (a *Projection[T]) GetPosition() int64 {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
 	return a.position
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"testing"
 	"time"
 )
@@ -55,4 +56,65 @@ func Test_Projection_WaitForPosition(t *testing.T) {
 		assert.Equal(t, "start-applying", <-events)
 		assert.Equal(t, "finished-waiting", <-events)
 	})
+
+	t.Run("returns straight away when the position is already reached", func(t *testing.T) {
+		p := NewProjection("a", evolveStringAppend)
+		assert.Nil(t, p.Apply(&appendEvent{S: "b"}, -1))
+
+		p.WaitForPosition(context.Background(), 0)
+
+		// The projection is still usable.
+		assert.Nil(t, p.Apply(&appendEvent{S: "c"}, 0))
+		assert.Equal(t, stringAppendState("abc"), p.GetState())
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		p := NewProjection("a", evolveStringAppend)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		p.WaitForPosition(ctx, 0)
+		assert.NotNil(t, ctx.Err())
+		assert.Equal(t, 0, len(p.waiters))
+	})
+}
+
+func Test_Projection_Concurrency(t *testing.T) {
+	t.Run("concurrent applies, reads and waits", func(t *testing.T) {
+		p := NewProjection("a", evolveStringAppend)
+		eventCount := int64(200)
+		wg := sync.WaitGroup{}
+
+		for i := int64(0); i < 10; i++ {
+			wg.Add(2)
+
+			position := i * eventCount / 10
+			go func() {
+				defer wg.Done()
+
+				p.WaitForPosition(context.Background(), position)
+				assert.GreaterOrEqual(t, p.GetPosition(), position)
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 50; j++ {
+					// The state always corresponds to its position.
+					p.ReadState(func(state stringAppendState, position int64) {
+						assert.Equal(t, int(position)+2, len(state))
+					})
+
+					_ = p.GetState()
+				}
+			}()
+		}
+
+		for i := int64(0); i < eventCount; i++ {
+			assert.Nil(t, p.Apply(&appendEvent{S: "b"}, i-1))
+		}
+
+		wg.Wait()
+		assert.Equal(t, eventCount-1, p.GetPosition())
+	})
 }
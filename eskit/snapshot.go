@@ -0,0 +1,202 @@
+package eskit
+
+import (
+	"context"
+	"errors"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
+	"strconv"
+)
+
+const (
+	snapshotPositionMetadata = "snapshot-position"
+	snapshotVersionMetadata  = "snapshot-version"
+)
+
+var errStaleSnapshot = errors.New("snapshot is ahead of its stream")
+
+// Snapshot is the state of a projection once the event at `Position` was applied.
+type Snapshot[T any] struct {
+	State    T
+	Position int64
+}
+
+type SnapshotStore[T any] interface {
+	// Load returns the latest snapshot of the stream, or nil if there is no compatible snapshot.
+	Load(ctx context.Context, stream string) (*Snapshot[T], error)
+
+	// Save persists the snapshot of the stream.
+	Save(ctx context.Context, stream string, snapshot Snapshot[T]) error
+}
+
+// SnapshotStreamFor returns the name of the companion stream in which the snapshots of
+// the given stream are stored.
+func SnapshotStreamFor(stream string) string {
+	return "$snapshot-" + stream
+}
+
+// StreamSnapshotStore stores snapshots as events in a companion stream. The state is encoded
+// with the codec, so its type needs to be known by the codec.
+// Snapshots saved with a different version are ignored: change the version when the state's
+// structure or the way it is evolved changes.
+type StreamSnapshotStore[T any] struct {
+	store   simplestore.Store
+	codec   codec.Codec
+	version string
+}
+
+func NewStreamSnapshotStore[T any](
+	store simplestore.Store,
+	codec codec.Codec,
+	version string,
+) *StreamSnapshotStore[T] {
+	return &StreamSnapshotStore[T]{
+		store:   store,
+		codec:   codec,
+		version: version,
+	}
+}
+
+func (s *StreamSnapshotStore[T]) Save(ctx context.Context, stream string, snapshot Snapshot[T]) error {
+	event, err := s.codec.Serialize(snapshot.State)
+	if err != nil {
+		return err
+	}
+
+	event.Metadata = map[string]string{
+		snapshotPositionMetadata: strconv.FormatInt(snapshot.Position, 10),
+		snapshotVersionMetadata:  s.version,
+	}
+
+	_, err = s.store.Write(ctx, []simplestore.AppendToStream{
+		{
+			Stream: SnapshotStreamFor(stream),
+			Events: []simplestore.Event{event},
+		},
+	})
+
+	return err
+}
+
+func (s *StreamSnapshotStore[T]) Load(ctx context.Context, stream string) (*Snapshot[T], error) {
+	ch := make(chan simplestore.ReadItem)
+	go s.store.Read(ctx, SnapshotStreamFor(stream), ch, simplestore.ReadOptions{
+		Backwards: true,
+		Limit:     1,
+	})
+
+	var latest *simplestore.Event
+	var err error
+	for item := range ch {
+		if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil {
+			latest = &item.EventInStream.Event
+		}
+	}
+
+	if err != nil || latest == nil {
+		return nil, err
+	}
+
+	return s.decode(*latest), nil
+}
+
+// decode returns the snapshot contained in the event, or nil if it is not compatible.
+func (s *StreamSnapshotStore[T]) decode(event simplestore.Event) *Snapshot[T] {
+	if event.Metadata[snapshotVersionMetadata] != s.version {
+		return nil
+	}
+
+	position, err := strconv.ParseInt(event.Metadata[snapshotPositionMetadata], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	decoded, err := s.codec.Deserialize(event)
+	if err != nil {
+		return nil
+	}
+
+	// Codecs decode messages as pointers, while the state might not be one.
+	switch state := decoded.(type) {
+	case T:
+		return &Snapshot[T]{State: state, Position: position}
+	case *T:
+		return &Snapshot[T]{State: *state, Position: position}
+	default:
+		return nil
+	}
+}
+
+// NewProjectionFromSnapshot builds the projection of the stream from its latest snapshot,
+// applying only the events written after it. Without a compatible snapshot, or if the snapshot
+// is ahead of the stream, the projection is built by replaying the whole stream.
+func NewProjectionFromSnapshot[T any](
+	ctx context.Context,
+	rw *ReaderWriter,
+	snapshots SnapshotStore[T],
+	stream string,
+	initialState T,
+	evolveFunc EvolveFunc[T],
+) (*Projection[T], error) {
+	snapshot, err := snapshots.Load(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot != nil {
+		projection := newProjectionAt(snapshot.State, snapshot.Position, evolveFunc)
+		err = replayStream(ctx, rw, stream, projection)
+		if !errors.Is(err, errStaleSnapshot) {
+			return projection, err
+		}
+	}
+
+	projection := NewProjection(initialState, evolveFunc)
+
+	return projection, replayStream(ctx, rw, stream, projection)
+}
+
+// replayStream applies the stream's events following the projection's position. When the
+// projection was built from a snapshot, the event at its position is expected to exist.
+func replayStream[T any](ctx context.Context, rw *ReaderWriter, stream string, projection *Projection[T]) error {
+	startingPosition := projection.GetPosition()
+	if startingPosition < 0 {
+		startingPosition = 0
+	}
+
+	ch := make(chan ReadItem)
+	go rw.Read(ctx, stream, startingPosition, ch)
+
+	var err error
+	snapshotEventFound := projection.GetPosition() < 0
+	for item := range ch {
+		if err != nil {
+			continue
+		} else if item.Error != nil {
+			err = item.Error
+		} else if item.EventInStream != nil {
+			if item.EventInStream.Position == projection.GetPosition() {
+				snapshotEventFound = true
+				continue
+			}
+
+			err = projection.Apply(item.EventInStream.Event, item.EventInStream.Position-1)
+		}
+	}
+
+	if err != nil {
+		return err
+	} else if !snapshotEventFound {
+		return errStaleSnapshot
+	}
+
+	return ctx.Err()
+}
+
+// shouldSnapshot returns true when a multiple of `frequency` events was crossed between
+// both positions.
+func shouldSnapshot(frequency int64, previousPosition int64, position int64) bool {
+	return frequency > 0 && (position+1)/frequency > (previousPosition+1)/frequency
+}
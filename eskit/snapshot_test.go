@@ -0,0 +1,97 @@
+package eskit
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Snapshots(t *testing.T) {
+	ss := NewInMemoryStore()
+	c := codec.NewGobCodec(appendEvent{}, stringAppendState(""))
+	rw := NewReaderWriter(ss, c)
+	snapshots := NewStreamSnapshotStore[stringAppendState](ss, c, "v1")
+
+	writeEvents := func(stream string, strings ...string) {
+		for _, s := range strings {
+			_, err := rw.Write([]EventToWrite{
+				{Stream: stream, Event: appendEvent{S: s}},
+			})
+			assert.Nil(t, err)
+		}
+	}
+
+	t.Run("loads the latest snapshot", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+
+		snapshot, err := snapshots.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Nil(t, snapshot)
+
+		assert.Nil(t, snapshots.Save(context.Background(), stream, Snapshot[stringAppendState]{State: "ab", Position: 0}))
+		assert.Nil(t, snapshots.Save(context.Background(), stream, Snapshot[stringAppendState]{State: "abc", Position: 1}))
+
+		snapshot, err = snapshots.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, &Snapshot[stringAppendState]{State: "abc", Position: 1}, snapshot)
+	})
+
+	t.Run("ignores snapshots of another version", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+
+		assert.Nil(t, snapshots.Save(context.Background(), stream, Snapshot[stringAppendState]{State: "ab", Position: 0}))
+
+		snapshot, err := NewStreamSnapshotStore[stringAppendState](ss, c, "v2").Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Nil(t, snapshot)
+	})
+
+	t.Run("applies only the events following the snapshot", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		writeEvents(stream, "b", "c", "d")
+
+		assert.Nil(t, snapshots.Save(context.Background(), stream, Snapshot[stringAppendState]{State: "snapshot", Position: 1}))
+
+		p, err := NewProjectionFromSnapshot[stringAppendState](context.Background(), rw, snapshots, stream, "a", evolveStringAppend)
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("snapshotd"), p.GetState())
+		assert.Equal(t, int64(2), p.GetPosition())
+	})
+
+	t.Run("replays the whole stream when the snapshot is ahead of it", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		writeEvents(stream, "b", "c")
+
+		assert.Nil(t, snapshots.Save(context.Background(), stream, Snapshot[stringAppendState]{State: "snapshot", Position: 5}))
+
+		p, err := NewProjectionFromSnapshot[stringAppendState](context.Background(), rw, snapshots, stream, "a", evolveStringAppend)
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("abc"), p.GetState())
+		assert.Equal(t, int64(1), p.GetPosition())
+	})
+
+	t.Run("repository saves a snapshot every few events", func(t *testing.T) {
+		stream := "foo/" + uuid.NewString()
+		repository := NewRepository(rw, Aggregate[stringAppendState, appendCommand]{
+			InitialState: "a",
+			Evolve:       evolveStringAppend,
+			Decide:       decideStringAppend,
+		})
+		repository.SetSnapshots(snapshots, 2)
+
+		for _, s := range []string{"b", "c", "d"} {
+			_, err := repository.Handle(context.Background(), stream, appendCommand{S: s})
+			assert.Nil(t, err)
+		}
+
+		snapshot, err := snapshots.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, &Snapshot[stringAppendState]{State: "abc", Position: 1}, snapshot)
+
+		p, err := repository.Load(context.Background(), stream)
+		assert.Nil(t, err)
+		assert.Equal(t, stringAppendState("abcd"), p.GetState())
+	})
+}
@@ -62,9 +62,12 @@ func (rw *ReaderWriter) Write(events []EventToWrite) ([]simplestore.AppendResult
 		commands[i] = simplestore.AppendToStream{
 			Stream: event.Stream,
 			Events: []simplestore.Event{serializedEvent},
-			Condition: &simplestore.AppendCondition{
+		}
+
+		if event.ExpectedPosition != nil {
+			commands[i].Condition = &simplestore.AppendCondition{
 				WriteAtPosition: *event.ExpectedPosition + 1,
-			},
+			}
 		}
 	}
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/simplestore"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -31,4 +32,41 @@ func Test_ReaderWriter(t *testing.T) {
 		item := <-ch
 		assert.Equal(t, &EventA{A: "foo"}, item.EventInStream.Event)
 	})
+
+	t.Run("upcasts events written with a previous schema", func(t *testing.T) {
+		stream := "test" + uuid.NewString()
+		store := NewInMemoryStore()
+
+		type EventAV1 struct {
+			B string
+		}
+
+		_, err := NewReaderWriter(store, codec.NewGobCodec(EventAV1{})).Write([]EventToWrite{
+			{Stream: stream, Event: EventAV1{B: "foo"}},
+		})
+		assert.Nil(t, err)
+
+		rw := NewReaderWriter(store, codec.NewVersionedCodec(
+			codec.NewGobCodec(EventA{}),
+			codec.Upcaster{
+				EventType:   "eskit.EventAV1",
+				FromVersion: 1,
+				Upcast: func(event simplestore.Event) (simplestore.Event, error) {
+					event.EventType = "eskit.EventA"
+
+					return event, nil
+				},
+			},
+			codec.NewGobUpcaster("eskit.EventA", 1, func(v1 EventAV1) EventA {
+				return EventA{A: v1.B}
+			}),
+		))
+
+		ch := make(chan ReadItem, 1)
+		go rw.Read(context.Background(), stream, 0, ch)
+
+		item := <-ch
+		assert.Nil(t, item.Error)
+		assert.Equal(t, &EventA{A: "foo"}, item.EventInStream.Event)
+	})
 }
@@ -14,7 +14,7 @@ func NewStore(db fdb.Database) *Store {
 	return &Store{db: &db}
 }
 
-func (s *Store) Get(key []byte) ([]byte, error) {
+func (s *Store) Get(ctx context.Context, key []byte) ([]byte, error) {
 	value, err := s.db.ReadTransact(func(transaction fdb.ReadTransaction) (interface{}, error) {
 		return transaction.Get(fdb.Key(key)).MustGet(), nil
 	})
@@ -26,7 +26,7 @@ func (s *Store) Get(key []byte) ([]byte, error) {
 	return value.([]byte), nil
 }
 
-func (s *Store) Write(operations []kv.Write) error {
+func (s *Store) Write(ctx context.Context, operations []kv.Write) error {
 	_, err := s.db.Transact(func(transaction fdb.Transaction) (interface{}, error) {
 		// TODO (perf): parallelize these operations!
 		for _, operation := range operations {
@@ -0,0 +1,63 @@
+package kv
+
+import (
+	"context"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/sroze/fossil/kv")
+
+// instrumentedKV measures and traces the requests to a KV backend.
+type instrumentedKV struct {
+	backend string
+	kv      KV
+}
+
+// Instrument returns the KV backend, measuring the round-trip time of its requests and tracing
+// them under the given backend name.
+func Instrument(backend string, kv KV) KV {
+	return &instrumentedKV{backend: backend, kv: kv}
+}
+
+func (k *instrumentedKV) Write(ctx context.Context, operations []Write) error {
+	ctx, end := k.start(ctx, "write", attribute.Int("fossil.kv.operations", len(operations)))
+	err := k.kv.Write(ctx, operations)
+	end(err)
+
+	return err
+}
+
+func (k *instrumentedKV) Get(ctx context.Context, key []byte) ([]byte, error) {
+	ctx, end := k.start(ctx, "get")
+	value, err := k.kv.Get(ctx, key)
+	end(err)
+
+	return value, err
+}
+
+// Scan is measured until all the keys have been consumed, so it includes the caller's time.
+func (k *instrumentedKV) Scan(ctx context.Context, keyRange KeyRange, options ScanOptions, ch chan KeyPair) error {
+	ctx, end := k.start(ctx, "scan", attribute.Bool("fossil.kv.backwards", options.Backwards), attribute.Int("fossil.kv.limit", options.Limit))
+	err := k.kv.Scan(ctx, keyRange, options, ch)
+	end(err)
+
+	return err
+}
+
+// start starts the span of the operation, and returns the function ending it.
+func (k *instrumentedKV) start(ctx context.Context, operation string, attributes ...attribute.KeyValue) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracer.Start(ctx, "kv."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		append(attributes, semconv.DBSystemKey.String(k.backend), semconv.DBOperationKey.String(operation))...,
+	))
+
+	return ctx, func(err error) {
+		requestDuration.WithLabelValues(k.backend, operation).Observe(time.Since(start).Seconds())
+		tracing.End(span, err)
+	}
+}
@@ -46,7 +46,7 @@ type ScanOptions struct {
 }
 
 type KV interface {
-	Write(operations []Write) error
-	Get(key []byte) ([]byte, error)
+	Write(ctx context.Context, operations []Write) error
+	Get(ctx context.Context, key []byte) ([]byte, error)
 	Scan(ctx context.Context, keyRange KeyRange, options ScanOptions, ch chan KeyPair) error
 }
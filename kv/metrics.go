@@ -0,0 +1,12 @@
+package kv
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "fossil_kv_request_duration_seconds",
+	Help:    "Round-trip time of the requests to the KV backend, by backend and operation (`get`, `write` or `scan`).",
+	Buckets: prometheus.ExponentialBuckets(0.0001, 2, 16),
+}, []string{"backend", "operation"})
@@ -1,9 +1,11 @@
 package pebble
 
 import (
+	"context"
 	"errors"
 	"github.com/cockroachdb/pebble"
 	"github.com/sroze/fossil/kv"
+	"io"
 	"sync"
 )
 
@@ -16,8 +18,15 @@ func NewStore(db *pebble.DB) *Store {
 	return &Store{db: db}
 }
 
-func (s *Store) Get(key []byte) ([]byte, error) {
-	value, closer, err := s.db.Get(key)
+func (s *Store) Get(ctx context.Context, key []byte) ([]byte, error) {
+	return get(func(key []byte) ([]byte, io.Closer, error) {
+		return s.db.Get(key)
+	}, key)
+}
+
+// get returns a copy of the value, as pebble's values are only valid until the closer is closed.
+func get(getter func(key []byte) ([]byte, io.Closer, error), key []byte) ([]byte, error) {
+	value, closer, err := getter(key)
 	if err != nil {
 		if errors.Is(err, pebble.ErrNotFound) {
 			return nil, nil
@@ -26,6 +35,7 @@ func (s *Store) Get(key []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	value = append([]byte{}, value...)
 	err = closer.Close()
 	if err != nil {
 		return nil, err
@@ -34,55 +44,79 @@ func (s *Store) Get(key []byte) ([]byte, error) {
 	return value, nil
 }
 
-func (s *Store) Scan(keyRange kv.KeyRange, options kv.ScanOptions) ([]kv.KeyPair, error) {
-	var keyPairs []kv.KeyPair
+func (s *Store) Scan(ctx context.Context, keyRange kv.KeyRange, options kv.ScanOptions, ch chan kv.KeyPair) error {
+	defer close(ch)
 
-	// FIXME: oblige to `options`
 	iter := s.db.NewIter(&pebble.IterOptions{
 		LowerBound: keyRange.Start,
 		UpperBound: keyRange.End,
 	})
 	defer iter.Close()
 
-	// Scan all key/value pairs in the database
-	for iter.First(); iter.Valid(); iter.Next() {
-		iterKey := iter.Key()
-		key := make([]byte, len(iterKey))
-		copy(key, iterKey)
+	first, next := iter.First, iter.Next
+	if options.Backwards {
+		first, next = iter.Last, iter.Prev
+	}
+
+	count := 0
+	for valid := first(); valid; valid = next() {
+		if options.Limit > 0 && count >= options.Limit {
+			break
+		}
 
 		value, err := iter.ValueAndErr()
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case ch <- kv.KeyPair{
+			Key:   append([]byte{}, iter.Key()...),
+			Value: append([]byte{}, value...),
+		}:
 		}
 
-		keyPairs = append(keyPairs, kv.KeyPair{Key: key, Value: value})
+		count++
 	}
 
-	return keyPairs, iter.Error()
+	return iter.Error()
 }
 
-func (s *Store) Write(operations []kv.Write) error {
+func (s *Store) Write(ctx context.Context, operations []kv.Write) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	b := s.db.NewBatch()
+	// The batch is indexed so that the conditions see the previous writes of the batch.
+	b := s.db.NewIndexedBatch()
 	defer b.Close()
 
 	for _, operation := range operations {
 		if operation.Condition != nil {
 			if operation.Condition.MustBeEmpty {
-				value, err := s.Get(operation.Key)
+				value, err := get(b.Get, operation.Key)
 				if err != nil {
 					return err
 				}
 
 				if value != nil {
-					return kv.ErrConditionalWriteFails
+					return kv.ErrConditionalWriteFails{
+						Condition:  operation.Condition,
+						Key:        operation.Key,
+						FoundValue: value,
+					}
 				}
 			}
 		}
 
-		err := b.Set(operation.Key, operation.Value, nil)
+		var err error
+		if operation.Value == nil {
+			err = b.Delete(operation.Key, nil)
+		} else {
+			err = b.Set(operation.Key, operation.Value, nil)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,7 @@
 package testing
 
 import (
+	"context"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/kv"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +16,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 	}
 
 	t.Run("write, get and scan", func(t *testing.T) {
-		err := s.Write([]kv.Write{
+		err := s.Write(context.Background(), []kv.Write{
 			{Key: prefixedKey([]byte("s/foo")), Value: []byte("foo")},
 			{Key: prefixedKey([]byte("s/bar")), Value: []byte("bar")},
 			{Key: prefixedKey([]byte("z/bar")), Value: []byte("bar")},
@@ -24,7 +25,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 
 		t.Run("it can scan keys in a given range", func(t *testing.T) {
 			scanCh := make(chan kv.KeyPair, 2)
-			err = s.Scan(kv.KeyRange{
+			err = s.Scan(context.Background(), kv.KeyRange{
 				Start: prefixedKey([]byte{'s', 0x00}),
 				End:   prefixedKey([]byte{'s', 0xFF}),
 			}, kv.ScanOptions{}, scanCh)
@@ -37,7 +38,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 
 		t.Run("it can scan backwards, with a limit", func(t *testing.T) {
 			scanCh := make(chan kv.KeyPair, 2)
-			err = s.Scan(kv.KeyRange{
+			err = s.Scan(context.Background(), kv.KeyRange{
 				Start: prefixedKey([]byte{'s', 0x00}),
 				End:   prefixedKey([]byte{'s', 0xFF}),
 			}, kv.ScanOptions{
@@ -52,13 +53,13 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 		})
 
 		t.Run("it get keys", func(t *testing.T) {
-			value, err := s.Get(prefixedKey([]byte("z/bar")))
+			value, err := s.Get(context.Background(), prefixedKey([]byte("z/bar")))
 			assert.Nil(t, err)
 			assert.Equal(t, []byte("bar"), value)
 		})
 
 		t.Run("it return nil for non-existing keys", func(t *testing.T) {
-			value, err := s.Get(prefixedKey([]byte("z/foo")))
+			value, err := s.Get(context.Background(), prefixedKey([]byte("z/foo")))
 			assert.Nil(t, err)
 			assert.Nil(t, value)
 		})
@@ -66,7 +67,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 
 	t.Run("it handles conditional writes", func(t *testing.T) {
 		t.Run("must be empty", func(t *testing.T) {
-			err := s.Write([]kv.Write{{
+			err := s.Write(context.Background(), []kv.Write{{
 				Key:   prefixedKey([]byte("does-not-exists")),
 				Value: []byte("foo"),
 				Condition: &kv.Condition{
@@ -76,7 +77,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 
 			assert.Nil(t, err)
 
-			err = s.Write([]kv.Write{{
+			err = s.Write(context.Background(), []kv.Write{{
 				Key:   prefixedKey([]byte("does-not-exists")),
 				Value: []byte("bar"),
 				Condition: &kv.Condition{
@@ -90,7 +91,7 @@ func RunAcceptanceTest(t *testing.T, s kv.KV) {
 	t.Run("catches concurrent writes", func(t *testing.T) {
 		results := make(chan error, 2)
 		write := func(value []byte) {
-			results <- s.Write([]kv.Write{{
+			results <- s.Write(context.Background(), []kv.Write{{
 				Key:   prefixedKey([]byte("another-key")),
 				Value: value,
 				Condition: &kv.Condition{
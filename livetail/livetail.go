@@ -2,6 +2,7 @@ package livetail
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/sroze/fossil/simplestore"
 	"strconv"
@@ -9,15 +10,21 @@ import (
 	"time"
 )
 
+var ErrStoppedBeforeEndOfStream = errors.New("livetail was stopped before reaching the end of the stream")
+
 type LiveTail struct {
 	// Provided by the user.
 	reader Reader
 
 	// Internal matters.
-	isEndOfStream bool
-	endOfStreamWg *sync.WaitGroup
-	ctx           context.Context
-	ctxCancel     context.CancelFunc
+	isEndOfStream    bool
+	endOfStreamErr   error
+	endOfStreamWg    *sync.WaitGroup
+	endOfStreamMutex sync.Mutex
+	started          bool
+	startedMutex     sync.Mutex
+	ctx              context.Context
+	ctxCancel        context.CancelFunc
 }
 
 func NewLiveTail(
@@ -26,20 +33,32 @@ func NewLiveTail(
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 
+	// The context is created upfront so that the tail can be stopped before being started.
+	ctx, ctxCancel := context.WithCancel(context.Background())
+
 	return &LiveTail{
 		reader:        reader,
 		endOfStreamWg: &wg,
+		ctx:           ctx,
+		ctxCancel:     ctxCancel,
 	}
 }
 
 func (a *LiveTail) Start(startingPosition string, ch chan simplestore.ReadItem) {
-	if a.ctx != nil {
+	a.startedMutex.Lock()
+	alreadyStarted := a.started
+	a.started = true
+	a.startedMutex.Unlock()
+
+	if alreadyStarted {
 		ch <- simplestore.ReadItem{Error: fmt.Errorf("livetail is already started")}
 
 		return
 	}
 
-	a.ctx, a.ctxCancel = context.WithCancel(context.Background())
+	activeLiveTails.Inc()
+	defer activeLiveTails.Dec()
+
 	chEvents := make(chan simplestore.ReadItem)
 	defer close(chEvents)
 
@@ -52,13 +71,11 @@ func (a *LiveTail) Start(startingPosition string, ch chan simplestore.ReadItem)
 			ch <- item
 
 			if item.EndOfStreamSignal != nil {
-				if !a.isEndOfStream {
-					a.isEndOfStream = true
-					a.endOfStreamWg.Done()
-				}
+				a.reachEndOfStream(nil)
 			}
 
 			if item.Error != nil {
+				a.reachEndOfStream(item.Error)
 				break
 			}
 		}
@@ -71,6 +88,7 @@ func (a *LiveTail) Start(startingPosition string, ch chan simplestore.ReadItem)
 		wg.Add(1)
 
 		nextPosition := position
+		polledEvents := 0
 
 		go func() {
 			defer wg.Done()
@@ -81,23 +99,38 @@ func (a *LiveTail) Start(startingPosition string, ch chan simplestore.ReadItem)
 					return
 				case item, more := <-readChannel:
 					if !more {
-						i, err := strconv.ParseInt(nextPosition, 10, 64)
-						if err != nil {
-							panic(err)
+						liveTailLag.Observe(float64(polledEvents))
+
+						// Cursors are not stream positions: the stream position is unknown.
+						streamPosition := int64(-1)
+						if i, err := strconv.ParseInt(nextPosition, 10, 64); err == nil {
+							streamPosition = i - 1
 						}
 
 						chEvents <- simplestore.ReadItem{
 							EndOfStreamSignal: &simplestore.EndOfStreamSignal{
-								StreamPosition: i - 1,
+								StreamPosition: streamPosition,
 							},
 						}
 
 						return
 					}
 
+					// The livetail sends its own signal once the read is done, as the readers
+					// with cursors don't know the position of the stream.
+					if item.EndOfStreamSignal != nil {
+						continue
+					}
+
 					chEvents <- item
 
 					if item.EventInStream != nil {
+						polledEvents++
+					}
+
+					if item.Cursor != "" {
+						nextPosition = item.Cursor
+					} else if item.EventInStream != nil {
 						nextPosition = strconv.FormatInt(item.EventInStream.Position+1, 10)
 					}
 				}
@@ -121,13 +154,34 @@ func (a *LiveTail) Start(startingPosition string, ch chan simplestore.ReadItem)
 	}
 }
 
-// TODO: stop waiting when the livetail fails and returns the error.
-func (a *LiveTail) WaitEndOfStream() {
+// reachEndOfStream releases the routines waiting for the end of the stream, with the error
+// that prevented to reach it, if any.
+func (a *LiveTail) reachEndOfStream(err error) {
+	a.endOfStreamMutex.Lock()
+	defer a.endOfStreamMutex.Unlock()
+
+	if a.isEndOfStream {
+		return
+	}
+
+	a.isEndOfStream = true
+	a.endOfStreamErr = err
+	a.endOfStreamWg.Done()
+}
+
+// WaitEndOfStream waits for the end of the stream to be reached for the first time. It returns
+// the error that happened before, if any.
+func (a *LiveTail) WaitEndOfStream() error {
 	a.endOfStreamWg.Wait()
+
+	a.endOfStreamMutex.Lock()
+	defer a.endOfStreamMutex.Unlock()
+
+	return a.endOfStreamErr
 }
 
 func (a *LiveTail) Stop() {
-	if a.ctx != nil {
-		a.ctxCancel()
-	}
+	a.ctxCancel()
+
+	a.reachEndOfStream(ErrStoppedBeforeEndOfStream)
 }
@@ -1,6 +1,7 @@
 package livetail
 
 import (
+	"context"
 	"github.com/apple/foundationdb/bindings/go/src/fdb"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/kv/foundationdb"
@@ -17,7 +18,7 @@ func Test_LiveTail(t *testing.T) {
 		stream := "Foo/" + uuid.NewString()
 
 		// Add one event to the stream.
-		_, err := ss.Write([]simplestore.AppendToStream{
+		_, err := ss.Write(context.Background(), []simplestore.AppendToStream{
 			{
 				Stream: stream,
 				Events: []simplestore.Event{
@@ -50,7 +51,7 @@ func Test_LiveTail(t *testing.T) {
 		assert.Equal(t, int64(0), item.EndOfStreamSignal.StreamPosition)
 
 		// Add another event, it should still continue follwing the stream.
-		_, err = ss.Write([]simplestore.AppendToStream{
+		_, err = ss.Write(context.Background(), []simplestore.AppendToStream{
 			{
 				Stream: stream,
 				Events: []simplestore.Event{
@@ -85,3 +86,41 @@ func Test_LiveTail(t *testing.T) {
 
 	t.Skip("TODO: live tail while a segment is closed + split")
 }
+
+type cursorReader struct {
+	startingPositions chan string
+}
+
+func (r *cursorReader) Read(ctx context.Context, startingPosition string, ch chan simplestore.ReadItem) {
+	defer close(ch)
+	r.startingPositions <- startingPosition
+
+	if startingPosition == "" {
+		ch <- simplestore.ReadItem{
+			EventInStream: &simplestore.EventInStream{Stream: "Foo/1", Position: 12},
+			Cursor:        "a-cursor",
+		}
+	}
+}
+
+func Test_LiveTail_Cursors(t *testing.T) {
+	reader := &cursorReader{startingPositions: make(chan string, 10)}
+	ch := make(chan simplestore.ReadItem, 10)
+	tail := NewLiveTail(reader)
+	go tail.Start("", ch)
+
+	item := <-ch
+	assert.Equal(t, "a-cursor", item.Cursor)
+
+	// The stream position of the end-of-stream is unknown.
+	item = <-ch
+	assert.Equal(t, int64(-1), item.EndOfStreamSignal.StreamPosition)
+
+	// Expects the next read to resume from the item's cursor, rather than its stream position.
+	assert.Equal(t, "", <-reader.startingPositions)
+	assert.Equal(t, "a-cursor", <-reader.startingPositions)
+
+	tail.Stop()
+	for range ch {
+	}
+}
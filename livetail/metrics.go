@@ -0,0 +1,20 @@
+package livetail
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	activeLiveTails = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fossil_livetails_active",
+		Help: "Number of live tails currently following a stream or a prefix.",
+	})
+
+	// A tail which keeps up reads few events per poll: many events mean it is lagging behind.
+	liveTailLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "fossil_livetail_lag_events",
+		Help:    "Number of events a live tail had to read to reach the end of the stream, per poll.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	})
+)
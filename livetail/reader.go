@@ -7,16 +7,22 @@ import (
 )
 
 type Reader interface {
-	// Read reads events and sends them to the channel.
+	// Read reads events and sends them to the channel, and closes it once done. Items are
+	// positioned by their stream position, unless they carry a cursor.
 	Read(ctx context.Context, startingPosition string, ch chan simplestore.ReadItem)
 }
 
+// StreamStore reads streams, like `simplestore.Store` and `store.Store` do.
+type StreamStore interface {
+	Read(ctx context.Context, stream string, ch chan simplestore.ReadItem, options simplestore.ReadOptions)
+}
+
 type StreamReader struct {
-	store  simplestore.Store
+	store  StreamStore
 	stream string
 }
 
-func NewStreamReader(store simplestore.Store, stream string) *StreamReader {
+func NewStreamReader(store StreamStore, stream string) *StreamReader {
 	return &StreamReader{
 		store:  store,
 		stream: stream,
@@ -57,14 +57,22 @@ func NewWatcher(
 }
 
 func (pw *Watcher) evolve(state WatcherState, event interface{}) WatcherState {
+	// The previous state might be read concurrently, so the nodes are copied.
+	availableNodes := make(map[uuid.UUID]Node, len(state.availableNodes))
+	for id, node := range state.availableNodes {
+		availableNodes[id] = node
+	}
+
 	switch e := event.(type) {
 	case *NodeJoinedEvent:
-		state.availableNodes[e.Node.Id] = e.Node
+		availableNodes[e.Node.Id] = e.Node
 	case *NodeLeftEvent:
-		delete(state.availableNodes, e.Node.Id)
+		delete(availableNodes, e.Node.Id)
 	}
 
-	return state
+	return WatcherState{
+		availableNodes: availableNodes,
+	}
 }
 
 func (pw *Watcher) compareAndDispatchPresence() error {
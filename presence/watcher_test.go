@@ -26,7 +26,7 @@ func Test_PresenceWatcher(t *testing.T) {
 
 		assert.Nil(t, pw.Start())
 		defer pw.Stop()
-		pw.projection.WaitEndOfStream()
+		assert.Nil(t, pw.projection.WaitEndOfStream())
 
 		ch := make(chan eskit.ReadItem)
 		go pw.rw.Read(context.Background(), stream, 0, ch)
@@ -0,0 +1,20 @@
+package simplestore_test
+
+import (
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/kv/foundationdb"
+	"github.com/sroze/fossil/simplestore"
+	simplestoreTesting "github.com/sroze/fossil/simplestore/testing"
+	"testing"
+)
+
+func Test_SimpleStore(t *testing.T) {
+	fdb.MustAPIVersion(720)
+	s := simplestore.NewStore(
+		foundationdb.NewStore(fdb.MustOpenDatabase("../fdb.cluster")),
+		uuid.NewString(),
+	)
+
+	simplestoreTesting.RunAcceptanceTest(t, s)
+}
@@ -29,13 +29,7 @@ func (ss *SimpleStore) Write(ctx context.Context, commands []AppendToStream) ([]
 		return results, err
 	}
 
-	writes, unlock, err := ss.TransformWritesAndAcquirePositionLock(ctx, preparedWrites)
-	defer unlock()
-	if err != nil {
-		return results, err
-	}
-
-	err = ss.kv.Write(writes)
+	err = ss.Commit(ctx, preparedWrites)
 	if err != nil {
 		_, err = ss.HandleError(err)
 	}
@@ -1,12 +1,16 @@
 package simplestore
 
 import (
+	"errors"
 	"github.com/apple/foundationdb/bindings/go/src/fdb"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/sroze/fossil/kv/foundationdb"
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	"testing"
+	"time"
 )
 
 func Test_Store_Append(t *testing.T) {
@@ -166,6 +170,67 @@ func Test_Store_Append(t *testing.T) {
 		assert.Equal(t, numberOfConcurrentRequests-1, numberOfFailures)
 	})
 
+	t.Run("concurrent writes are grouped in a batch", func(t *testing.T) {
+		previousLingerTime := GroupCommitLingerTime
+		GroupCommitLingerTime = 20 * time.Millisecond
+		defer func() { GroupCommitLingerTime = previousLingerTime }()
+
+		// The number of batches, and of writes they grouped, committed in the segment so far.
+		committedBatches := func() (uint64, float64) {
+			m := &dto.Metric{}
+			assert.Nil(t, groupCommitBatchSize.WithLabelValues(string(s.keySpace)).(prometheus.Histogram).Write(m))
+
+			return m.Histogram.GetSampleCount(), m.Histogram.GetSampleSum()
+		}
+		batchesBefore, writesBefore := committedBatches()
+
+		conflictingStream := "Foo/" + uuid.NewString()
+		_, err := s.Write(context.Background(), GenerateStreamWriteRequests(conflictingStream, 1))
+		assert.Nil(t, err)
+
+		numberOfConcurrentRequests := 10
+		resultChan := make(chan error, numberOfConcurrentRequests)
+		for i := 0; i < numberOfConcurrentRequests; i++ {
+			go func() {
+				_, err := s.Write(context.Background(), GenerateStreamWriteRequests("Foo/"+uuid.NewString(), 1))
+				resultChan <- err
+			}()
+		}
+
+		// This write will fail within the batch, without failing the others.
+		_, err = s.Write(context.Background(), []AppendToStream{{
+			Stream: conflictingStream,
+			Condition: &AppendCondition{
+				WriteAtPosition: 0,
+			},
+			Events: []Event{{
+				EventId:   uuid.NewString(),
+				EventType: "AnEventType",
+				Payload:   []byte("{\"foo\": 123}"),
+			}},
+		}})
+		assert.NotNil(t, err)
+		assert.IsType(t, StreamConditionFailed{}, err)
+
+		for i := 0; i < numberOfConcurrentRequests; i++ {
+			assert.Nil(t, <-resultChan)
+		}
+
+		batchesAfter, writesAfter := committedBatches()
+		assert.GreaterOrEqual(t, writesAfter-writesBefore, float64(numberOfConcurrentRequests))
+		assert.Less(t, float64(batchesAfter-batchesBefore), writesAfter-writesBefore)
+	})
+
+	t.Run("writes fail once the store is stopped", func(t *testing.T) {
+		stopped := NewStore(s.kv, uuid.NewString())
+		_, err := stopped.Write(context.Background(), GenerateStreamWriteRequests("Foo/"+uuid.NewString(), 1))
+		assert.Nil(t, err)
+
+		stopped.Stop()
+		_, err = stopped.Write(context.Background(), GenerateStreamWriteRequests("Foo/"+uuid.NewString(), 1))
+		assert.True(t, errors.Is(err, StoreIsStoppedErr))
+	})
+
 	t.Run("it can start a stream at a specific position", func(t *testing.T) {
 		stream := "Foo/" + uuid.NewString()
 		r, err := s.Write(context.Background(), []AppendToStream{
@@ -0,0 +1,96 @@
+package simplestore
+
+import (
+	"context"
+	"fmt"
+	"github.com/bits-and-blooms/bloom/v3"
+	"github.com/sroze/fossil/kv"
+)
+
+// BloomFilterFalsePositiveRate is the false positive rate targeted when sizing the bloom
+// filter of a closed segment.
+var BloomFilterFalsePositiveRate = 0.01
+
+func (ss *SimpleStore) bloomFilterKey() []byte {
+	return kv.ConcatBytes(ss.keySpace, []byte("/b"))
+}
+
+// prepareBloomFilterKvWrite builds a bloom filter of all the streams that have events in the
+// segment. It is written alongside the close event: as the segment is immutable once closed,
+// the filter never needs to be updated.
+func (ss *SimpleStore) prepareBloomFilterKvWrite(ctx context.Context) (kv.Write, error) {
+	kpChan := make(chan kv.KeyPair)
+	scanErr := make(chan error, 1)
+	go func() {
+		scanErr <- ss.kv.Scan(ctx, ss.streamIndexedKeyFactory.RangeForAllStreams(), kv.ScanOptions{}, kpChan)
+	}()
+
+	// Keys are ordered by stream, so we only need to compare with the previous one to
+	// get the distinct stream names.
+	var streams []string
+	for kp := range kpChan {
+		stream, _, err := ss.streamIndexedKeyFactory.Reverse(kp.Key)
+		if err != nil {
+			return kv.Write{}, err
+		}
+
+		if len(streams) == 0 || streams[len(streams)-1] != stream {
+			streams = append(streams, stream)
+		}
+	}
+
+	if err := <-scanErr; err != nil {
+		return kv.Write{}, fmt.Errorf("unable to scan streams: %w", err)
+	}
+
+	filter := bloom.NewWithEstimates(uint(len(streams)+1), BloomFilterFalsePositiveRate)
+	for _, stream := range streams {
+		filter.AddString(stream)
+	}
+
+	value, err := filter.MarshalBinary()
+	if err != nil {
+		return kv.Write{}, fmt.Errorf("unable to encode bloom filter: %w", err)
+	}
+
+	return kv.Write{
+		Key:   ss.bloomFilterKey(),
+		Value: value,
+		Condition: &kv.Condition{
+			MustBeEmpty: true,
+		},
+	}, nil
+}
+
+// MightContainStream returns `false` if the segment definitely does not contain any event for
+// the given stream, based on the bloom filter written when the segment was closed.
+// It must only be called for closed segments: the filter (or its absence, for segments closed
+// before bloom filters were introduced) is cached for the lifetime of the store.
+func (ss *SimpleStore) MightContainStream(ctx context.Context, stream string) (bool, error) {
+	ss.bloomFilterMutex.Lock()
+	defer ss.bloomFilterMutex.Unlock()
+
+	if !ss.bloomFilterLoaded {
+		value, err := ss.kv.Get(ctx, ss.bloomFilterKey())
+		if err != nil {
+			return true, fmt.Errorf("unable to get bloom filter: %w", err)
+		}
+
+		if value != nil {
+			filter := &bloom.BloomFilter{}
+			if err := filter.UnmarshalBinary(value); err != nil {
+				return true, fmt.Errorf("unable to decode bloom filter: %w", err)
+			}
+
+			ss.bloomFilter = filter
+		}
+
+		ss.bloomFilterLoaded = true
+	}
+
+	if ss.bloomFilter == nil {
+		return true, nil
+	}
+
+	return ss.bloomFilter.TestString(stream), nil
+}
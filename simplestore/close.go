@@ -25,6 +25,11 @@ func (ss *SimpleStore) PrepareCloseKvWrites(ctx context.Context) ([]kv.Write, er
 		return nil, fmt.Errorf("unable to encode close event: %w", err)
 	}
 
+	bloomFilterWrite, err := ss.prepareBloomFilterKvWrite(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to prepare bloom filter: %w", err)
+	}
+
 	return []kv.Write{
 		{
 			Key:   ss.positionIndexedKeyFactory.Bytes(segmentPosition + 1),
@@ -33,5 +38,6 @@ func (ss *SimpleStore) PrepareCloseKvWrites(ctx context.Context) ([]kv.Write, er
 				MustBeEmpty: true,
 			},
 		},
+		bloomFilterWrite,
 	}, nil
 }
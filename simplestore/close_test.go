@@ -18,7 +18,7 @@ func Test_Close(t *testing.T) {
 		storeToBeClosed := NewStore(kvs, uuid.NewString())
 		writes, err := storeToBeClosed.PrepareCloseKvWrites(context.Background())
 		assert.Nil(t, err)
-		err = kvs.Write(writes)
+		err = kvs.Write(context.Background(), writes)
 		assert.Nil(t, err)
 
 		_, err = storeToBeClosed.Write(context.Background(), []AppendToStream{
@@ -32,4 +32,36 @@ func Test_Close(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.True(t, errors.Is(err, StoreIsClosedErr{}))
 	})
+
+	t.Run("a closed store has a bloom filter of its streams", func(t *testing.T) {
+		kvs := foundationdb.NewStore(fdb.MustOpenDatabase("../fdb.cluster"))
+		storeToBeClosed := NewStore(kvs, uuid.NewString())
+
+		writes, eventIdsPerStream := GenerateEventWriteRequests(5, 2, "Foo/")
+		_, err := storeToBeClosed.Write(context.Background(), writes)
+		assert.Nil(t, err)
+
+		closeWrites, err := storeToBeClosed.PrepareCloseKvWrites(context.Background())
+		assert.Nil(t, err)
+		err = kvs.Write(context.Background(), closeWrites)
+		assert.Nil(t, err)
+
+		for stream := range eventIdsPerStream {
+			mightContain, err := storeToBeClosed.MightContainStream(context.Background(), stream)
+			assert.Nil(t, err)
+			assert.True(t, mightContain)
+		}
+
+		// Bloom filters can have false positives, so we only expect most of the unknown streams to be excluded.
+		falsePositives := 0
+		for i := 0; i < 100; i++ {
+			mightContain, err := storeToBeClosed.MightContainStream(context.Background(), "Bar/"+uuid.NewString())
+			assert.Nil(t, err)
+
+			if mightContain {
+				falsePositives++
+			}
+		}
+		assert.Less(t, falsePositives, 10)
+	})
 }
@@ -26,6 +26,8 @@ func (e StreamConditionFailed) Error() string {
 
 var SegmentConcurrentWriteErr = errors.New("concurrent write on segment")
 
+var StoreIsStoppedErr = errors.New("store is stopped")
+
 func (ss *SimpleStore) HandleError(err error) (bool, error) {
 	conditionFailed, isConditionFailed := err.(kv.ErrConditionalWriteFails)
 
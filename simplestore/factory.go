@@ -1,6 +1,7 @@
 package simplestore
 
 import (
+	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/sroze/fossil/kv"
 	"sync"
 )
@@ -12,8 +13,17 @@ type SimpleStore struct {
 	positionIndexedKeyFactory *PositionIndexedEventKeyFactory
 	streamIndexedKeyFactory   *StreamIndexEventKeyFactory
 
-	positionMutex sync.Mutex
+	positionLock  *positionLock
 	positionCache *int64
+
+	bloomFilterMutex  sync.Mutex
+	bloomFilterLoaded bool
+	bloomFilter       *bloom.BloomFilter
+
+	committerOnce  sync.Once
+	commitRequests chan commitRequest
+	stopOnce       sync.Once
+	stopped        chan struct{}
 }
 
 func NewStore(kv kv.KV, keySpace string) *SimpleStore {
@@ -22,5 +32,7 @@ func NewStore(kv kv.KV, keySpace string) *SimpleStore {
 		keySpace:                  []byte(keySpace),
 		positionIndexedKeyFactory: &PositionIndexedEventKeyFactory{keySpace: []byte(keySpace)},
 		streamIndexedKeyFactory:   &StreamIndexEventKeyFactory{keySpace: []byte(keySpace)},
+		positionLock:              newPositionLock(),
+		stopped:                   make(chan struct{}),
 	}
 }
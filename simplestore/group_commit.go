@@ -0,0 +1,230 @@
+package simplestore
+
+import (
+	"context"
+	"github.com/sroze/fossil/kv"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+var (
+	// GroupCommitMaxBatchSize is the maximum number of concurrent writes grouped in a single
+	// KV transaction.
+	GroupCommitMaxBatchSize = 100
+
+	// GroupCommitLingerTime is how long the segment writer waits for more writes to arrive
+	// before committing a batch. With `0`, only the writes that arrived while the previous
+	// batch was being committed are grouped together.
+	GroupCommitLingerTime = time.Duration(0)
+)
+
+type commitRequest struct {
+	ctx      context.Context
+	prepared []PreparedWrite
+	result   chan error
+}
+
+// Commit writes the prepared writes in the KV store. Concurrent commits on the same segment are
+// grouped in a single KV transaction, in which they get contiguous segment positions.
+// As for `kv.Write`, the returned error is the raw KV error: use `HandleError` to transform it.
+// If the context is cancelled once the write was handed over to the segment writer, the write
+// might still be committed.
+func (ss *SimpleStore) Commit(ctx context.Context, prepared []PreparedWrite) error {
+	select {
+	case <-ss.stopped:
+		return StoreIsStoppedErr
+	default:
+	}
+
+	ss.committerOnce.Do(func() {
+		ss.commitRequests = make(chan commitRequest)
+		go ss.runCommitter()
+	})
+
+	request := commitRequest{
+		ctx:      ctx,
+		prepared: prepared,
+		result:   make(chan error, 1),
+	}
+
+	select {
+	case ss.commitRequests <- request:
+	case <-ss.stopped:
+		return StoreIsStoppedErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-request.result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stop stops the segment writer. The writes handed over before are still committed, while the
+// subsequent ones fail with `StoreIsStoppedErr`.
+func (ss *SimpleStore) Stop() {
+	ss.stopOnce.Do(func() {
+		close(ss.stopped)
+	})
+}
+
+func (ss *SimpleStore) runCommitter() {
+	for {
+		batch := ss.collectBatch()
+		if batch == nil {
+			return
+		}
+
+		ss.commitBatch(batch)
+	}
+}
+
+// collectBatch returns the next batch of writes, or nil once the store is stopped.
+func (ss *SimpleStore) collectBatch() []commitRequest {
+	var batch []commitRequest
+	select {
+	case request := <-ss.commitRequests:
+		batch = append(batch, request)
+	case <-ss.stopped:
+		return nil
+	}
+
+	var linger <-chan time.Time
+	if GroupCommitLingerTime > 0 {
+		linger = time.After(GroupCommitLingerTime)
+	}
+
+	for len(batch) < GroupCommitMaxBatchSize {
+		if linger == nil {
+			select {
+			case request := <-ss.commitRequests:
+				batch = append(batch, request)
+			default:
+				return batch
+			}
+		} else {
+			select {
+			case request := <-ss.commitRequests:
+				batch = append(batch, request)
+			case <-linger:
+				return batch
+			}
+		}
+	}
+
+	return batch
+}
+
+// commitBatch writes the batch in a single KV transaction. If a write fails because of its
+// stream condition, it is removed from the batch and the remaining writes are retried.
+func (ss *SimpleStore) commitBatch(batch []commitRequest) {
+	// The batch has its own trace, linked to the traces of the grouped writes.
+	links := make([]trace.Link, len(batch))
+	for i, request := range batch {
+		links[i] = trace.LinkFromContext(request.ctx)
+	}
+
+	groupCommitBatchSize.WithLabelValues(string(ss.keySpace)).Observe(float64(len(batch)))
+
+	ctx, span := tracer.Start(context.Background(), "simplestore.commitBatch", trace.WithLinks(links...), trace.WithAttributes(
+		tracing.SegmentIdKey.String(string(ss.keySpace)),
+		attribute.Int("fossil.batch_size", len(batch)),
+	))
+	defer span.End()
+
+	err := ss.positionLock.Lock(ctx, string(ss.keySpace))
+	if err != nil {
+		resolveBatch(batch, err)
+		return
+	}
+
+	defer ss.positionLock.Unlock()
+
+	for len(batch) > 0 {
+		var pending []commitRequest
+		for _, request := range batch {
+			if err := request.ctx.Err(); err != nil {
+				request.result <- err
+				continue
+			}
+
+			pending = append(pending, request)
+		}
+
+		batch = pending
+		if len(batch) == 0 {
+			return
+		}
+
+		var previousPosition *int64
+		if ss.positionCache != nil {
+			p := *ss.positionCache
+			previousPosition = &p
+		}
+
+		var writes []kv.Write
+		keyOwners := make(map[string]int)
+		for i, request := range batch {
+			w, err := ss.transformWrites(request.ctx, request.prepared)
+			if err != nil {
+				ss.positionCache = previousPosition
+				resolveBatch(batch, err)
+				return
+			}
+
+			for _, write := range w {
+				keyOwners[string(write.Key)] = i
+			}
+
+			writes = append(writes, w...)
+		}
+
+		err := ss.kv.Write(ctx, writes)
+		if err == nil {
+			resolveBatch(batch, nil)
+			return
+		}
+
+		// Nothing was written, so the positions can be given again.
+		ss.positionCache = previousPosition
+
+		conditionFailed, isConditionFailed := err.(kv.ErrConditionalWriteFails)
+		if !isConditionFailed {
+			resolveBatch(batch, err)
+			return
+		}
+
+		owner, found := keyOwners[string(conditionFailed.Key)]
+		if found && ss.isStreamKey(conditionFailed.Key) {
+			// Only this write's stream condition failed, the others can be retried.
+			batch[owner].result <- err
+			batch = append(batch[:owner], batch[owner+1:]...)
+			continue
+		}
+
+		if _, positionErr := ss.positionIndexedKeyFactory.Reverse(conditionFailed.Key); positionErr == nil {
+			// Another writer has written in the segment: our position is stale.
+			ss.positionCache = nil
+		}
+
+		resolveBatch(batch, err)
+		return
+	}
+}
+
+func (ss *SimpleStore) isStreamKey(key []byte) bool {
+	_, _, err := ss.streamIndexedKeyFactory.Reverse(key)
+
+	return err == nil
+}
+
+func resolveBatch(batch []commitRequest, err error) {
+	for _, request := range batch {
+		request.result <- err
+	}
+}
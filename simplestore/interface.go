@@ -12,6 +12,10 @@ type ReadItem struct {
 	EventInStream     *EventInStream
 	EndOfStreamSignal *EndOfStreamSignal
 	Error             error
+
+	// Cursor from which reading resumes after this item, set by the readers which positions
+	// are not stream positions (e.g. queries across segments).
+	Cursor string
 }
 
 type QueryItem struct {
@@ -43,7 +47,7 @@ type EventInStream struct {
 }
 
 type EndOfStreamSignal struct {
-	// Position of the stream. It is the position of next to-be-written event (or aldo
-	// described as the number of events in the stream).
+	// Position of the stream, i.e. the position of its last event. It is `-1` when the
+	// stream is empty.
 	StreamPosition int64
 }
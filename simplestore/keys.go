@@ -57,6 +57,14 @@ func (k StreamIndexEventKeyFactory) Range(stream string) kv.KeyRange {
 	return kv.NewPrefixKeyRange(k.streamKeyPrefix(stream))
 }
 
+// RangeForAllStreams returns the range containing the events of every stream in the key space.
+func (k StreamIndexEventKeyFactory) RangeForAllStreams() kv.KeyRange {
+	return kv.NewPrefixKeyRange(kv.ConcatBytes(
+		k.keySpace,
+		[]byte("/s/"),
+	))
+}
+
 func (k StreamIndexEventKeyFactory) RangeStartingAt(stream string, startingPosition int64) kv.KeyRange {
 	prefix := kv.ConcatBytes(
 		k.keySpace,
@@ -0,0 +1,148 @@
+package simplestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"time"
+)
+
+var (
+	// PositionLockMaxWaitTime is the maximum time a writer waits to acquire a segment's position lock.
+	PositionLockMaxWaitTime = 5 * time.Second
+
+	// PositionLockMaxHoldTime is the maximum time a writer can hold a segment's position lock. Once
+	// elapsed, the lock is released so that a crashed client routine doesn't block the segment forever,
+	// and the writes made with it are rejected.
+	PositionLockMaxHoldTime = 10 * time.Second
+)
+
+// PositionLockExpiredErr is returned when writing with a position lock held for longer than
+// `PositionLockMaxHoldTime`.
+var PositionLockExpiredErr = errors.New("position lock expired")
+
+type PositionLockTimeoutErr struct {
+	KeySpace string
+}
+
+func (e PositionLockTimeoutErr) Error() string {
+	return fmt.Sprintf("timed out acquiring the position lock of %s", e.KeySpace)
+}
+
+// positionLock is a mutex that can be acquired with a context.
+type positionLock struct {
+	ch chan struct{}
+}
+
+func newPositionLock() *positionLock {
+	return &positionLock{
+		ch: make(chan struct{}, 1),
+	}
+}
+
+// Lock acquires the lock, or returns an error if the context is done or the lock could not be
+// acquired within `PositionLockMaxWaitTime`.
+func (l *positionLock) Lock(ctx context.Context, keySpace string) error {
+	start := time.Now()
+	_, span := tracer.Start(ctx, "simplestore.positionLock", trace.WithAttributes(
+		tracing.SegmentIdKey.String(keySpace),
+	))
+
+	err := l.lock(ctx, keySpace)
+	positionLockWaitDuration.WithLabelValues(keySpace).Observe(time.Since(start).Seconds())
+	tracing.End(span, err)
+
+	return err
+}
+
+func (l *positionLock) lock(ctx context.Context, keySpace string) error {
+	ctx, cancel := context.WithTimeout(ctx, PositionLockMaxWaitTime)
+	defer cancel()
+
+	select {
+	case l.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return PositionLockTimeoutErr{KeySpace: keySpace}
+		}
+
+		return ctx.Err()
+	}
+}
+
+func (l *positionLock) Unlock() {
+	<-l.ch
+}
+
+// PositionLease is the hold of a position lock, which expires after `PositionLockMaxHoldTime`.
+type PositionLease struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	unlock   func()
+	released sync.Once
+
+	// Held while writing, so that the lease doesn't expire in the middle of a write.
+	mutex   sync.Mutex
+	expired bool
+}
+
+// Lease acquires the lock for at most `PositionLockMaxHoldTime`. Once elapsed, the lease's context
+// is cancelled and, once the in-flight write returned, `onExpiry` is called before the lock is
+// released, so that the state guarded by the lock can be reset for the next holder.
+func (l *positionLock) Lease(ctx context.Context, keySpace string, onExpiry func()) (*PositionLease, error) {
+	err := l.Lock(ctx, keySpace)
+	if err != nil {
+		return nil, err
+	}
+
+	lease := &PositionLease{unlock: l.Unlock}
+	lease.ctx, lease.cancel = context.WithCancel(ctx)
+	lease.timer = time.AfterFunc(PositionLockMaxHoldTime, func() {
+		lease.cancel()
+
+		lease.mutex.Lock()
+		lease.expired = true
+		onExpiry()
+		lease.mutex.Unlock()
+
+		lease.release()
+	})
+
+	return lease, nil
+}
+
+// Context returns the context to write with, which is cancelled once the lease expired or was
+// released.
+func (l *PositionLease) Context() context.Context {
+	return l.ctx
+}
+
+// Do runs the write, unless the lease has expired. The lease can't expire while the write runs.
+func (l *PositionLease) Do(write func() error) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.expired {
+		return PositionLockExpiredErr
+	}
+
+	return write()
+}
+
+// Release releases the lock. Releasing it again, or once expired, is a no-op.
+func (l *PositionLease) Release() {
+	l.timer.Stop()
+	l.release()
+}
+
+func (l *PositionLease) release() {
+	l.released.Do(func() {
+		l.cancel()
+		l.unlock()
+	})
+}
@@ -0,0 +1,83 @@
+package simplestore
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_PositionLock(t *testing.T) {
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		l := newPositionLock()
+		assert.Nil(t, l.Lock(context.Background(), "foo"))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			cancel()
+		}()
+
+		err := l.Lock(ctx, "foo")
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("returns a timeout error when the lock can't be acquired in time", func(t *testing.T) {
+		l := newPositionLock()
+		assert.Nil(t, l.Lock(context.Background(), "foo"))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		defer cancel()
+
+		err := l.Lock(ctx, "foo")
+		assert.Equal(t, PositionLockTimeoutErr{KeySpace: "foo"}, err)
+	})
+
+	t.Run("expires leases after the maximum hold time", func(t *testing.T) {
+		l := newPositionLock()
+		expired := false
+		PositionLockMaxHoldTime = 5 * time.Millisecond
+		lease, err := l.Lease(context.Background(), "foo", func() {
+			expired = true
+		})
+		assert.Nil(t, err)
+		PositionLockMaxHoldTime = 10 * time.Second
+
+		secondLease, err := l.Lease(context.Background(), "foo", func() {})
+		assert.Nil(t, err)
+		assert.True(t, expired)
+
+		// The expired lease can't be written with anymore.
+		assert.NotNil(t, lease.Context().Err())
+		assert.Equal(t, PositionLockExpiredErr, lease.Do(func() error {
+			return nil
+		}))
+
+		// Releasing the expired lease doesn't release the lock held by the second writer.
+		lease.Release()
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Millisecond)
+		defer cancel()
+		assert.NotNil(t, l.Lock(ctx, "foo"))
+
+		secondLease.Release()
+		assert.Nil(t, l.Lock(context.Background(), "foo"))
+	})
+
+	t.Run("does not expire leases while writing", func(t *testing.T) {
+		l := newPositionLock()
+		PositionLockMaxHoldTime = 5 * time.Millisecond
+		lease, err := l.Lease(context.Background(), "foo", func() {})
+		assert.Nil(t, err)
+		PositionLockMaxHoldTime = 10 * time.Second
+
+		err = lease.Do(func() error {
+			<-lease.Context().Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+			defer cancel()
+
+			return l.Lock(ctx, "foo")
+		})
+		assert.Equal(t, PositionLockTimeoutErr{KeySpace: "foo"}, err)
+	})
+}
@@ -1,16 +1,40 @@
 package simplestore
 
 import (
+	"context"
 	"fmt"
+	"go.opentelemetry.io/otel/propagation"
+	"strings"
 )
 
+// ReservedMetadataPrefix is the prefix of the metadata keys that are set by the store itself.
+const ReservedMetadataPrefix = "$"
+
+const streamMetadataKey = ReservedMetadataPrefix + "stream"
+
+func IsReservedMetadataKey(key string) bool {
+	return strings.HasPrefix(key, ReservedMetadataPrefix)
+}
+
+// WithoutReservedMetadata returns the metadata that were set by the writer of the event.
+func WithoutReservedMetadata(metadata map[string]string) map[string]string {
+	filtered := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !IsReservedMetadataKey(k) {
+			filtered[k] = v
+		}
+	}
+
+	return filtered
+}
+
 func AddStreamToMetadata(event Event, stream string) Event {
 	eventMetadata := map[string]string{
-		"$stream": stream,
+		streamMetadataKey: stream,
 	}
 
 	for k, v := range event.Metadata {
-		if k == "$stream" {
+		if k == streamMetadataKey {
 			continue
 		}
 
@@ -26,10 +50,55 @@ func AddStreamToMetadata(event Event, stream string) Event {
 }
 
 func GetStreamFromMetadata(event Event) (string, error) {
-	stream, ok := event.Metadata["$stream"]
+	stream, ok := event.Metadata[streamMetadataKey]
 	if !ok {
 		return "", fmt.Errorf("event #%s has no stream metadata", event.EventId)
 	}
 
 	return stream, nil
 }
+
+// traceContextPropagator reads and writes the W3C trace context (`traceparent` and `tracestate`).
+var traceContextPropagator = propagation.TraceContext{}
+
+// AddTraceContextToMetadata records the trace of the context in the event's metadata, so that
+// the event's consumers can relate their work to the write (i.e. causal tracing).
+func AddTraceContextToMetadata(ctx context.Context, event Event) Event {
+	eventMetadata := make(map[string]string, len(event.Metadata)+2)
+	for k, v := range event.Metadata {
+		eventMetadata[k] = v
+	}
+
+	traceContextPropagator.Inject(ctx, metadataCarrier(eventMetadata))
+	event.Metadata = eventMetadata
+
+	return event
+}
+
+// TraceContextFromMetadata returns the context with the trace recorded in the event's metadata,
+// if any.
+func TraceContextFromMetadata(ctx context.Context, event Event) context.Context {
+	return traceContextPropagator.Extract(ctx, metadataCarrier(event.Metadata))
+}
+
+// metadataCarrier stores the trace context under reserved metadata keys.
+type metadataCarrier map[string]string
+
+func (c metadataCarrier) Get(key string) string {
+	return c[ReservedMetadataPrefix+key]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+	c[ReservedMetadataPrefix+key] = value
+}
+
+func (c metadataCarrier) Keys() []string {
+	var keys []string
+	for k := range c {
+		if IsReservedMetadataKey(k) {
+			keys = append(keys, strings.TrimPrefix(k, ReservedMetadataPrefix))
+		}
+	}
+
+	return keys
+}
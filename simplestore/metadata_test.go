@@ -1,7 +1,9 @@
 package simplestore
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
 	"testing"
 )
 
@@ -27,3 +29,46 @@ func Test_StreamInMetadata(t *testing.T) {
 		assert.Equal(t, stream, streamFromEvent)
 	})
 }
+
+func Test_ReservedMetadata(t *testing.T) {
+	t.Run("removes the reserved keys", func(t *testing.T) {
+		event := AddStreamToMetadata(Event{
+			EventId: "123",
+			Metadata: map[string]string{
+				"correlation-id": "456",
+			},
+		}, "foo/bar")
+
+		assert.True(t, IsReservedMetadataKey("$stream"))
+		assert.Equal(t, map[string]string{"correlation-id": "456"}, WithoutReservedMetadata(event.Metadata))
+	})
+}
+
+func Test_TraceContextInMetadata(t *testing.T) {
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	t.Run("records the trace context under reserved keys", func(t *testing.T) {
+		event := AddTraceContextToMetadata(ctx, Event{
+			EventId:  "123",
+			Metadata: map[string]string{"correlation-id": "456"},
+		})
+
+		assert.NotEmpty(t, event.Metadata["$traceparent"])
+		assert.Equal(t, map[string]string{"correlation-id": "456"}, WithoutReservedMetadata(event.Metadata))
+
+		extracted := trace.SpanContextFromContext(TraceContextFromMetadata(context.Background(), event))
+		assert.Equal(t, spanContext.TraceID(), extracted.TraceID())
+		assert.Equal(t, spanContext.SpanID(), extracted.SpanID())
+	})
+
+	t.Run("records nothing without a trace", func(t *testing.T) {
+		event := AddTraceContextToMetadata(context.Background(), Event{EventId: "123"})
+		assert.Equal(t, map[string]string{}, event.Metadata)
+		assert.False(t, trace.SpanContextFromContext(TraceContextFromMetadata(context.Background(), event)).IsValid())
+	})
+}
@@ -0,0 +1,20 @@
+package simplestore
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	positionLockWaitDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fossil_segment_position_lock_wait_seconds",
+		Help:    "Time spent waiting to acquire a segment's position lock, by segment.",
+		Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+	}, []string{"segment"})
+
+	groupCommitBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fossil_segment_group_commit_batch_size",
+		Help:    "Number of writes grouped in a single KV transaction, by segment.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 8),
+	}, []string{"segment"})
+)
@@ -2,6 +2,7 @@ package simplestore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/sroze/fossil/kv"
 )
@@ -88,3 +89,15 @@ func (ss *SimpleStore) fetchSegmentPosition(ctx context.Context) (int64, error)
 
 	return position, err
 }
+
+// Head returns the position of the segment's last event, `-1` when it has none, and whether the
+// segment is closed.
+func (ss *SimpleStore) Head(ctx context.Context) (int64, bool, error) {
+	position, err := ss.fetchSegmentPosition(ctx)
+	if errors.Is(err, StoreIsClosedErr{}) {
+		// The last position is the one of the close event.
+		return position - 1, true, nil
+	}
+
+	return position, false, err
+}
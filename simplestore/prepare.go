@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/sroze/fossil/kv"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Challenge: increase writer throughput.
@@ -14,6 +16,18 @@ import (
 // Transform these "prepared statements" into real statements (with the positioning), and execute them.
 
 func (ss *SimpleStore) PrepareKvWrites(ctx context.Context, commands []AppendToStream) ([]PreparedWrite, []AppendResult, error) {
+	ctx, span := tracer.Start(ctx, "simplestore.PrepareKvWrites", trace.WithAttributes(
+		tracing.SegmentIdKey.String(string(ss.keySpace)),
+		tracing.EventCountKey.Int(countEvents(commands)),
+	))
+
+	writes, results, err := ss.prepareKvWrites(ctx, commands)
+	tracing.End(span, err)
+
+	return writes, results, err
+}
+
+func (ss *SimpleStore) prepareKvWrites(ctx context.Context, commands []AppendToStream) ([]PreparedWrite, []AppendResult, error) {
 	// TODO: cache (https://github.com/coocood/freecache)
 	streamPositionCursors := make(map[string]int64)
 
@@ -87,18 +101,44 @@ func (ss *SimpleStore) PrepareKvWrites(ctx context.Context, commands []AppendToS
 	return writes, results, nil
 }
 
-// TODO: add a pipeline here to allow for concurrent writes within the single segment while keeping
-// TODO: the same architecture (i.e. one Fossil -> KV store roundtrip at a time)
-// TODO: cancel the lock if context is cancelled.
-func (ss *SimpleStore) TransformWritesAndAcquirePositionLock(ctx context.Context, prepared []PreparedWrite) ([]kv.Write, func(), error) {
-	ss.positionMutex.Lock()
+// TransformWritesAndAcquirePositionLock acquires the segment's position lock and transforms the prepared
+// writes with their segment positions. The writes are to be sent within the returned lease's `Do`, which
+// rejects them once the lock was held for longer than `PositionLockMaxHoldTime`. The lease must be released.
+func (ss *SimpleStore) TransformWritesAndAcquirePositionLock(ctx context.Context, prepared []PreparedWrite) ([]kv.Write, *PositionLease, error) {
+	lease, err := ss.positionLock.Lease(ctx, string(ss.keySpace), func() {
+		// The positions given to the expired holder might not have been written: they are read
+		// again from the KV store.
+		ss.positionCache = nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var writes []kv.Write
+	err = lease.Do(func() error {
+		var err error
+		writes, err = ss.transformWrites(lease.Context(), prepared)
+
+		return err
+	})
+	if err != nil {
+		lease.Release()
 
+		return nil, nil, err
+	}
+
+	return writes, lease, nil
+}
+
+// transformWrites replaces the segment position placeholders with actual positions. The
+// caller must hold the `positionLock`.
+func (ss *SimpleStore) transformWrites(ctx context.Context, prepared []PreparedWrite) ([]kv.Write, error) {
 	var writes []kv.Write
 	for _, preparedWrite := range prepared {
 		if bytes.Equal(preparedWrite.Key, SegmentPositionPlaceholderMagicBytes) {
 			position, err := ss.getIncrementedSegmentPosition(ctx)
 			if err != nil {
-				return nil, func() {}, fmt.Errorf("failed to get incremented segment position: %w", err)
+				return nil, fmt.Errorf("failed to get incremented segment position: %w", err)
 			}
 
 			preparedWrite.Key = ss.positionIndexedKeyFactory.Bytes(position)
@@ -111,9 +151,16 @@ func (ss *SimpleStore) TransformWritesAndAcquirePositionLock(ctx context.Context
 		})
 	}
 
-	// TODO: we want to add a timeout here, so that if the client routine crashes,
-	//       we don't keep the lock forever.
-	return writes, ss.positionMutex.Unlock, nil
+	return writes, nil
+}
+
+func countEvents(commands []AppendToStream) int {
+	count := 0
+	for _, command := range commands {
+		count += len(command.Events)
+	}
+
+	return count
 }
 
 type PreparedWrite struct {
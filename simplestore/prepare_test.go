@@ -7,6 +7,7 @@ import (
 	"github.com/sroze/fossil/kv/foundationdb"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func Test_Prepare(t *testing.T) {
@@ -38,18 +39,64 @@ func Test_Prepare(t *testing.T) {
 		assert.Equal(t, int64(0), optimisticResults2[0].Position)
 
 		// get segment position and lock
-		w1, unlock, err := s.TransformWritesAndAcquirePositionLock(context.Background(), prepared)
-		unlock()
+		w1, lease, err := s.TransformWritesAndAcquirePositionLock(context.Background(), prepared)
 		assert.Nil(t, err)
+		lease.Release()
 
-		w2, unlock, err := s.TransformWritesAndAcquirePositionLock(context.Background(), prepared2)
-		unlock()
+		w2, lease, err := s.TransformWritesAndAcquirePositionLock(context.Background(), prepared2)
 		assert.Nil(t, err)
+		lease.Release()
 
-		err = s.kv.Write(append(w1, w2...))
+		err = s.kv.Write(context.Background(), append(w1, w2...))
 		assert.Nil(t, err)
 	})
 
+	t.Run("rejects the writes of a lock holder which outlived the maximum hold time", func(t *testing.T) {
+		s := NewStore(s.kv, uuid.NewString())
+		event := func() []Event {
+			return []Event{{EventId: uuid.NewString(), EventType: "Foo", Payload: []byte("foo")}}
+		}
+
+		prepared, _, err := s.PrepareKvWrites(context.Background(), []AppendToStream{
+			{Stream: "Foo/" + uuid.NewString(), Events: event()},
+		})
+		assert.Nil(t, err)
+
+		PositionLockMaxHoldTime = 5 * time.Millisecond
+		w, lease, err := s.TransformWritesAndAcquirePositionLock(context.Background(), prepared)
+		PositionLockMaxHoldTime = 10 * time.Second
+		assert.Nil(t, err)
+		defer lease.Release()
+
+		// Another writer commits once the lock has expired.
+		_, err = s.Write(context.Background(), []AppendToStream{
+			{Stream: "Foo/" + uuid.NewString(), Events: event()},
+		})
+		assert.Nil(t, err)
+
+		err = lease.Do(func() error {
+			return s.kv.Write(lease.Context(), w)
+		})
+		assert.Equal(t, PositionLockExpiredErr, err)
+
+		_, err = s.Write(context.Background(), []AppendToStream{
+			{Stream: "Foo/" + uuid.NewString(), Events: event()},
+		})
+		assert.Nil(t, err)
+
+		// The segment positions were given in order, only once.
+		ch := make(chan QueryItem)
+		go s.Query(context.Background(), "Foo/", 0, ch)
+
+		var positions []int64
+		for item := range ch {
+			assert.Nil(t, item.Error)
+			positions = append(positions, item.Position)
+		}
+
+		assert.Equal(t, []int64{0, 1}, positions)
+	})
+
 	t.Run("given a stream with events", func(t *testing.T) {
 		// Given a stream with 2 events
 		stream := "Foo/" + uuid.NewString()
@@ -76,11 +123,11 @@ func Test_Prepare(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, int64(0), er[0].Position)
 
-			w, unlock, err := s.TransformWritesAndAcquirePositionLock(context.Background(), pw)
-			unlock()
+			w, lease, err := s.TransformWritesAndAcquirePositionLock(context.Background(), pw)
 			assert.Nil(t, err)
+			lease.Release()
 
-			err = s.kv.Write(w)
+			err = s.kv.Write(context.Background(), w)
 			assert.NotNil(t, err)
 
 			known, err := s.HandleError(err)
@@ -104,11 +151,11 @@ func Test_Prepare(t *testing.T) {
 			assert.Nil(t, err)
 			assert.Equal(t, int64(1), er[0].Position)
 
-			w, unlock, err := s.TransformWritesAndAcquirePositionLock(context.Background(), pw)
-			unlock()
+			w, lease, err := s.TransformWritesAndAcquirePositionLock(context.Background(), pw)
 			assert.Nil(t, err)
+			lease.Release()
 
-			err = s.kv.Write(w)
+			err = s.kv.Write(context.Background(), w)
 			assert.NotNil(t, err)
 
 			known, err := s.HandleError(err)
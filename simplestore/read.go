@@ -5,45 +5,78 @@ import (
 	"github.com/sroze/fossil/kv"
 )
 
+// Read sends the events of the stream. Forward reads which reach the end of the stream end
+// with an `EndOfStreamSignal`.
 func (ss *SimpleStore) Read(ctx context.Context, stream string, ch chan ReadItem, options ReadOptions) {
+	defer close(ch)
+
 	keyCh := make(chan kv.KeyPair)
+	scanErr := make(chan error, 1)
 	go func() {
-		defer close(ch)
-
-		for keyPair := range keyCh {
-			stream, position, err := ss.streamIndexedKeyFactory.Reverse(keyPair.Key)
-			if err != nil {
-				ch <- ReadItem{Error: err}
-				return
-			}
-
-			event, err := DecodeEvent(keyPair.Value)
-			if err != nil {
-				ch <- ReadItem{Error: err}
-				return
-			}
-
-			ch <- ReadItem{
-				EventInStream: &EventInStream{
-					Position: position,
-					Event:    *event,
-					Stream:   stream,
-				},
-			}
-		}
+		scanErr <- ss.kv.Scan(
+			ctx,
+			ss.streamIndexedKeyFactory.RangeStartingAt(stream, options.StartingPosition),
+			kv.ScanOptions{
+				Backwards: options.Backwards,
+				Limit:     options.Limit,
+			},
+			keyCh,
+		)
 	}()
 
-	err := ss.kv.Scan(
-		ctx,
-		ss.streamIndexedKeyFactory.RangeStartingAt(stream, options.StartingPosition),
-		kv.ScanOptions{
-			Backwards: options.Backwards,
-			Limit:     options.Limit,
-		},
-		keyCh,
-	)
+	var err error
+	count, head := 0, int64(-1)
+	for keyPair := range keyCh {
+		// The rest of the scan is drained once an item fails to be decoded.
+		if err != nil {
+			continue
+		}
+
+		var event *Event
+		var position int64
+		_, position, err = ss.streamIndexedKeyFactory.Reverse(keyPair.Key)
+		if err == nil {
+			event, err = DecodeEvent(keyPair.Value)
+		}
+
+		if err != nil {
+			ch <- ReadItem{Error: err}
+			continue
+		}
+
+		ch <- ReadItem{
+			EventInStream: &EventInStream{
+				Position: position,
+				Event:    *event,
+				Stream:   stream,
+			},
+		}
+
+		count++
+		head = position
+	}
 
 	if err != nil {
+		return
+	} else if err = <-scanErr; err != nil {
 		ch <- ReadItem{Error: err}
+		return
+	}
+
+	if options.Backwards || (options.Limit > 0 && count >= options.Limit) || ctx.Err() != nil {
+		return
+	}
+
+	// The stream's head is before the starting position.
+	if count == 0 && options.StartingPosition > 0 {
+		head, err = ss.fetchStreamPosition(ctx, stream)
+		if err != nil {
+			ch <- ReadItem{Error: err}
+			return
+		}
+	}
+
+	ch <- ReadItem{
+		EndOfStreamSignal: &EndOfStreamSignal{StreamPosition: head},
 	}
 }
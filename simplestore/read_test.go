@@ -65,7 +65,10 @@ func Test_Read(t *testing.T) {
 			}
 		}
 
-		// Expects the stream to be closed.
+		// Expects the end of the stream to be signaled before the channel is closed.
+		item := <-ch
+		assert.Equal(t, &EndOfStreamSignal{StreamPosition: int64(len(dummyEventIds) - 1)}, item.EndOfStreamSignal)
+
 		_, more := <-ch
 		assert.False(t, more)
 	})
@@ -0,0 +1,276 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/exp/slices"
+	"testing"
+)
+
+// RunAcceptanceTest is a 'reference' test implementation for all the `simplestore.Store` implementations.
+func RunAcceptanceTest(t *testing.T, s simplestore.Store) {
+	prefix := "tests/" + uuid.NewString() + "/"
+
+	write := func(stream string, count int) []string {
+		writes := simplestore.GenerateStreamWriteRequests(stream, count)
+		_, err := s.Write(context.Background(), writes)
+		assert.Nil(t, err)
+
+		eventIds := make([]string, len(writes))
+		for i, w := range writes {
+			eventIds[i] = w.Events[0].EventId
+		}
+
+		return eventIds
+	}
+
+	// readUntilEnd returns the read events, and the end of stream signal if any.
+	readUntilEnd := func(stream string, options simplestore.ReadOptions) ([]string, []int64, *simplestore.EndOfStreamSignal) {
+		ch := make(chan simplestore.ReadItem)
+		go s.Read(context.Background(), stream, ch, options)
+
+		var eventIds []string
+		var positions []int64
+		var endOfStream *simplestore.EndOfStreamSignal
+		for item := range ch {
+			assert.Nil(t, item.Error)
+			assert.Nil(t, endOfStream, "expected the end of stream signal to be the last item")
+
+			if item.EventInStream != nil {
+				assert.Equal(t, stream, item.EventInStream.Stream)
+				eventIds = append(eventIds, item.EventInStream.Event.EventId)
+				positions = append(positions, item.EventInStream.Position)
+			}
+
+			if item.EndOfStreamSignal != nil {
+				endOfStream = item.EndOfStreamSignal
+			}
+		}
+
+		return eventIds, positions, endOfStream
+	}
+
+	read := func(stream string, options simplestore.ReadOptions) ([]string, []int64) {
+		eventIds, positions, _ := readUntilEnd(stream, options)
+
+		return eventIds, positions
+	}
+
+	t.Run("write and read", func(t *testing.T) {
+		t.Run("events are read in order, with their content", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			event := simplestore.Event{
+				EventId:   uuid.NewString(),
+				EventType: "Foo",
+				Payload:   []byte("foo"),
+				Metadata:  map[string]string{"correlation-id": "123"},
+			}
+
+			r, err := s.Write(context.Background(), []simplestore.AppendToStream{{
+				Stream: stream,
+				Events: []simplestore.Event{event, {EventId: uuid.NewString(), EventType: "Bar"}},
+			}})
+			assert.Nil(t, err)
+			assert.Equal(t, []simplestore.AppendResult{{Position: 1}}, r)
+
+			ch := make(chan simplestore.ReadItem)
+			go s.Read(context.Background(), stream, ch, simplestore.ReadOptions{})
+
+			item := <-ch
+			assert.Nil(t, item.Error)
+			assert.Equal(t, int64(0), item.EventInStream.Position)
+			assert.Equal(t, event, item.EventInStream.Event)
+
+			item = <-ch
+			assert.Equal(t, int64(1), item.EventInStream.Position)
+			assert.Equal(t, "Bar", item.EventInStream.Event.EventType)
+
+			item = <-ch
+			assert.Equal(t, &simplestore.EndOfStreamSignal{StreamPosition: 1}, item.EndOfStreamSignal)
+
+			// Expects the channel to be closed at the end of the stream.
+			_, more := <-ch
+			assert.False(t, more)
+		})
+
+		t.Run("increments the stream position by default", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			write(stream, 2)
+
+			r, err := s.Write(context.Background(), simplestore.GenerateStreamWriteRequests(stream, 1))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(2), r[0].Position)
+		})
+
+		t.Run("writes multiple commands at once", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			otherStream := prefix + uuid.NewString()
+
+			r, err := s.Write(context.Background(), append(
+				simplestore.GenerateStreamWriteRequests(stream, 2),
+				simplestore.GenerateStreamWriteRequests(otherStream, 1)...,
+			))
+			assert.Nil(t, err)
+			assert.Equal(t, []simplestore.AppendResult{{Position: 0}, {Position: 1}, {Position: 0}}, r)
+		})
+
+		t.Run("reading an unknown stream returns nothing", func(t *testing.T) {
+			eventIds, _, endOfStream := readUntilEnd(prefix+uuid.NewString(), simplestore.ReadOptions{})
+			assert.Empty(t, eventIds)
+			assert.Equal(t, &simplestore.EndOfStreamSignal{StreamPosition: -1}, endOfStream)
+		})
+	})
+
+	t.Run("conditions", func(t *testing.T) {
+		writeAt := func(stream string, condition simplestore.AppendCondition) error {
+			_, err := s.Write(context.Background(), []simplestore.AppendToStream{{
+				Stream:    stream,
+				Events:    []simplestore.Event{{EventId: uuid.NewString(), EventType: "Foo"}},
+				Condition: &condition,
+			}})
+
+			return err
+		}
+
+		t.Run("expects the stream to be empty", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			assert.Nil(t, writeAt(stream, simplestore.AppendCondition{StreamIsEmpty: true}))
+
+			var conditionFailed simplestore.StreamConditionFailed
+			assert.True(t, errors.As(writeAt(stream, simplestore.AppendCondition{StreamIsEmpty: true}), &conditionFailed))
+			assert.Equal(t, stream, conditionFailed.Stream)
+		})
+
+		t.Run("starts an empty stream at the given position", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			assert.Nil(t, writeAt(stream, simplestore.AppendCondition{StreamIsEmpty: true, WriteAtPosition: 3}))
+
+			r, err := s.Write(context.Background(), simplestore.GenerateStreamWriteRequests(stream, 1))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(4), r[0].Position)
+
+			_, positions := read(stream, simplestore.ReadOptions{})
+			assert.Equal(t, []int64{3, 4}, positions)
+
+			var conditionFailed simplestore.StreamConditionFailed
+			assert.True(t, errors.As(writeAt(stream, simplestore.AppendCondition{StreamIsEmpty: true, WriteAtPosition: 3}), &conditionFailed))
+			assert.Equal(t, stream, conditionFailed.Stream)
+		})
+
+		t.Run("expects a specific stream position", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			write(stream, 3)
+
+			var conditionFailed simplestore.StreamConditionFailed
+			assert.True(t, errors.As(writeAt(stream, simplestore.AppendCondition{WriteAtPosition: 2}), &conditionFailed))
+			assert.Equal(t, stream, conditionFailed.Stream)
+
+			assert.Nil(t, writeAt(stream, simplestore.AppendCondition{WriteAtPosition: 3}))
+		})
+
+		t.Run("rejects invalid stream positions", func(t *testing.T) {
+			err := writeAt(prefix+uuid.NewString(), simplestore.AppendCondition{WriteAtPosition: -1})
+			assert.NotNil(t, err)
+			assert.False(t, errors.As(err, &simplestore.StreamConditionFailed{}))
+		})
+
+		t.Run("writes nothing when a condition fails", func(t *testing.T) {
+			stream := prefix + uuid.NewString()
+			otherStream := prefix + uuid.NewString()
+			write(otherStream, 1)
+
+			_, err := s.Write(context.Background(), []simplestore.AppendToStream{
+				{Stream: stream, Events: []simplestore.Event{{EventId: uuid.NewString(), EventType: "Foo"}}},
+				{Stream: otherStream, Events: []simplestore.Event{{EventId: uuid.NewString(), EventType: "Foo"}}, Condition: &simplestore.AppendCondition{StreamIsEmpty: true}},
+			})
+			assert.True(t, errors.As(err, &simplestore.StreamConditionFailed{}))
+
+			eventIds, _ := read(stream, simplestore.ReadOptions{})
+			assert.Empty(t, eventIds)
+		})
+	})
+
+	t.Run("read options", func(t *testing.T) {
+		stream := prefix + uuid.NewString()
+		eventIds := write(stream, 10)
+
+		t.Run("from a starting position", func(t *testing.T) {
+			readEventIds, positions, endOfStream := readUntilEnd(stream, simplestore.ReadOptions{StartingPosition: 4})
+			assert.Equal(t, eventIds[4:], readEventIds)
+			assert.Equal(t, []int64{4, 5, 6, 7, 8, 9}, positions)
+			assert.Equal(t, &simplestore.EndOfStreamSignal{StreamPosition: 9}, endOfStream)
+		})
+
+		t.Run("from a starting position after the end of the stream", func(t *testing.T) {
+			readEventIds, _, endOfStream := readUntilEnd(stream, simplestore.ReadOptions{StartingPosition: 20})
+			assert.Empty(t, readEventIds)
+			assert.Equal(t, &simplestore.EndOfStreamSignal{StreamPosition: 9}, endOfStream)
+		})
+
+		t.Run("with a limit", func(t *testing.T) {
+			readEventIds, _, endOfStream := readUntilEnd(stream, simplestore.ReadOptions{Limit: 3})
+			assert.Equal(t, eventIds[:3], readEventIds)
+			assert.Nil(t, endOfStream)
+		})
+
+		t.Run("backwards", func(t *testing.T) {
+			readEventIds, positions, endOfStream := readUntilEnd(stream, simplestore.ReadOptions{Backwards: true, Limit: 3})
+			assert.Nil(t, endOfStream)
+
+			expectedEventIds := slices.Clone(eventIds[7:])
+			slices.Reverse(expectedEventIds)
+			assert.Equal(t, expectedEventIds, readEventIds)
+			assert.Equal(t, []int64{9, 8, 7}, positions)
+		})
+
+		t.Run("backwards down to a starting position", func(t *testing.T) {
+			readEventIds, _ := read(stream, simplestore.ReadOptions{Backwards: true, StartingPosition: 8})
+			assert.Equal(t, []string{eventIds[9], eventIds[8]}, readEventIds)
+		})
+	})
+
+	t.Run("query", func(t *testing.T) {
+		queryPrefix := prefix + uuid.NewString() + "/"
+		writes, eventsPerStream := simplestore.GenerateEventWriteRequests(3, 4, queryPrefix)
+		_, err := s.Write(context.Background(), writes)
+		assert.Nil(t, err)
+
+		// Streams with names shorter than the prefix, or with another prefix.
+		write("a", 1)
+		write(prefix+uuid.NewString(), 1)
+
+		query := func(startingPosition int64) ([]simplestore.QueryItem, map[string][]string) {
+			ch := make(chan simplestore.QueryItem)
+			go s.Query(context.Background(), queryPrefix, startingPosition, ch)
+
+			var items []simplestore.QueryItem
+			collected := make(map[string][]string)
+			for item := range ch {
+				assert.Nil(t, item.Error)
+				items = append(items, item)
+				collected[item.EventInStream.Stream] = append(collected[item.EventInStream.Stream], item.EventInStream.Event.EventId)
+			}
+
+			return items, collected
+		}
+
+		t.Run("returns the events of the prefixed streams in order", func(t *testing.T) {
+			items, collected := query(0)
+			assert.Equal(t, eventsPerStream, collected)
+
+			for i := 1; i < len(items); i++ {
+				assert.Greater(t, items[i].Position, items[i-1].Position)
+			}
+		})
+
+		t.Run("resumes from a position", func(t *testing.T) {
+			items, _ := query(0)
+			resumedItems, _ := query(items[4].Position + 1)
+
+			assert.Equal(t, items[5:], resumedItems)
+		})
+	})
+}
@@ -0,0 +1,5 @@
+package simplestore
+
+import "go.opentelemetry.io/otel"
+
+var tracer = otel.Tracer("github.com/sroze/fossil/simplestore")
@@ -0,0 +1,41 @@
+package store
+
+import (
+	"context"
+	"github.com/sroze/fossil/eskit"
+)
+
+// EventSource exposes the store's queries as an `eskit.EventSource`, using the position
+// cursors as cursors.
+type EventSource struct {
+	store *Store
+}
+
+func NewEventSource(store *Store) *EventSource {
+	return &EventSource{
+		store: store,
+	}
+}
+
+func (s *EventSource) Query(ctx context.Context, prefix string, cursor string, ch chan eskit.SourceItem) {
+	defer close(ch)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	intermediaryCh := make(chan QueryItem)
+	go s.store.Query(ctx, prefix, PositionCursor(cursor), intermediaryCh)
+
+	// The store's query does not close the channel when it fails, so we stop at the first error.
+	for item := range intermediaryCh {
+		if item.Error != nil {
+			ch <- eskit.SourceItem{Error: item.Error}
+			return
+		}
+
+		ch <- eskit.SourceItem{
+			EventInStream: item.EventInStream,
+			Cursor:        string(*item.Position),
+		}
+	}
+}
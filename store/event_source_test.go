@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store/segments"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_EventSource(t *testing.T) {
+	withFreshStore(t, func(ctx testingContext) {
+		_, err := ctx.store.topologyManager.Create(segments.NewSegment(
+			segments.NewPrefixRange("foo"),
+		))
+		assert.Nil(t, err)
+
+		writes, _ := simplestore.GenerateEventWriteRequests(2, 2, "foo/")
+		commands, err := mergeCommandsPerStream(writes)
+		assert.Nil(t, err)
+
+		_, err = ctx.store.Write(context.Background(), commands)
+		assert.Nil(t, err)
+
+		source := NewEventSource(ctx.store)
+		query := func(cursor string) []eskit.SourceItem {
+			ch := make(chan eskit.SourceItem)
+			go source.Query(context.Background(), "foo/", cursor, ch)
+
+			var items []eskit.SourceItem
+			for item := range ch {
+				assert.Nil(t, item.Error)
+				items = append(items, item)
+			}
+
+			return items
+		}
+
+		t.Run("queries from the beginning and resumes from a cursor", func(t *testing.T) {
+			items := query("")
+			assert.Equal(t, 4, len(items))
+
+			resumedItems := query(items[1].Cursor)
+			assert.Equal(t, items[2:], resumedItems)
+		})
+	})
+}
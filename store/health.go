@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/store/topology"
+	"time"
+)
+
+// KVProbeTimeout is how long the KV backend has to answer the readiness probe before being
+// considered unreachable.
+var KVProbeTimeout = 5 * time.Second
+
+// kvProbeKey is read to check that the KV backend is reachable. It does not need to exist.
+var kvProbeKey = []byte("$health")
+
+// Ready returns an error explaining why the store can't serve requests: the topology is not
+// loaded yet or failed to be, no segment can be written into, or the KV backend is unreachable.
+func (s *Store) Ready(ctx context.Context) error {
+	health := s.topologyManager.Health()
+	switch health.Status {
+	case eskit.HealthFailed:
+		return fmt.Errorf("topology failed: %w", health.Err)
+	case eskit.HealthRunning:
+		return fmt.Errorf("topology is not loaded yet")
+	}
+
+	if !s.topologyManager.GetState().HasWritableSegment() {
+		return topology.NoSegmentToWriteIntoError{}
+	}
+
+	return s.probeKV(ctx)
+}
+
+// probeKV reads a key from the KV backend. As some backends retry forever when they can't be
+// reached, the read is abandoned after `KVProbeTimeout`.
+func (s *Store) probeKV(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, KVProbeTimeout)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.kv.Get(ctx, kvProbeKey)
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("kv is unreachable: %w", err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("kv is unreachable: %w", ctx.Err())
+	}
+}
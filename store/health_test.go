@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"github.com/sroze/fossil/kv"
+	"github.com/sroze/fossil/store/segments"
+	"github.com/sroze/fossil/store/topology"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// unreachableKV fails, or blocks, the reads.
+type unreachableKV struct {
+	kv.KV
+	err error
+}
+
+func (k unreachableKV) Get(ctx context.Context, key []byte) ([]byte, error) {
+	if k.err != nil {
+		return nil, k.err
+	}
+
+	select {}
+}
+
+func Test_Ready(t *testing.T) {
+	withFreshStore(t, func(ctx testingContext) {
+		t.Run("is not ready without a segment to write into", func(t *testing.T) {
+			err := ctx.store.Ready(context.Background())
+			assert.Equal(t, topology.NoSegmentToWriteIntoError{}, err)
+		})
+
+		_, err := ctx.store.topologyManager.Create(segments.NewSegment(
+			segments.NewPrefixRange(""),
+		))
+		assert.Nil(t, err)
+
+		t.Run("is ready with a segment to write into", func(t *testing.T) {
+			assert.Nil(t, ctx.store.Ready(context.Background()))
+		})
+
+		t.Run("is not ready when the KV backend fails", func(t *testing.T) {
+			store := *ctx.store
+			store.kv = unreachableKV{KV: ctx.kv, err: errors.New("connection refused")}
+
+			assert.ErrorContains(t, store.Ready(context.Background()), "connection refused")
+		})
+
+		t.Run("is not ready when the KV backend does not answer", func(t *testing.T) {
+			previousTimeout := KVProbeTimeout
+			KVProbeTimeout = 10 * time.Millisecond
+			defer func() { KVProbeTimeout = previousTimeout }()
+
+			store := *ctx.store
+			store.kv = unreachableKV{KV: ctx.kv}
+
+			assert.ErrorIs(t, store.Ready(context.Background()), context.DeadlineExceeded)
+		})
+	})
+}
@@ -0,0 +1,57 @@
+package store
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// The false-positive rate of the bloom filters is `false_positives / (false_positives + checks{result="skipped"})`.
+	bloomFilterChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fossil_segment_bloom_filter_checks_total",
+		Help: "Number of closed segments checked against their bloom filter when reading a stream, by result (`skipped` or `read`).",
+	}, []string{"result"})
+
+	bloomFilterFalsePositives = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fossil_segment_bloom_filter_false_positives_total",
+		Help: "Number of closed segments read because of their bloom filter but which did not contain the stream.",
+	})
+
+	streamPositionCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fossil_stream_position_cache_lookups_total",
+		Help: "Number of lookups of a stream head position in the node's cache, by result (`hit` or `miss`).",
+	}, []string{"result"})
+
+	writeRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "fossil_write_retries_total",
+		Help: "Number of times a write was retried, by reason.",
+	}, []string{"reason"})
+
+	appendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fossil_append_duration_seconds",
+		Help:    "Time taken to commit the events appended in a segment, by segment.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"segment"})
+
+	appendBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fossil_append_batch_size",
+		Help:    "Number of events appended in a segment by a single write, by segment.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"segment"})
+
+	segmentConcurrentWrites = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fossil_segment_concurrent_write_errors_total",
+		Help: "Number of write attempts that failed because another writer wrote in the segment.",
+	})
+
+	streamConditionFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "fossil_stream_condition_failures_total",
+		Help: "Number of write attempts that failed because a stream was not at the expected position.",
+	})
+
+	eventsRead = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "fossil_events_read",
+		Help:    "Number of events returned by a single read, by operation (`read` or `query`).",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"operation"})
+)
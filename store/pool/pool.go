@@ -11,6 +11,7 @@ type SimpleStorePool struct {
 	kv                 kv.KV
 	segmentStores      map[uuid.UUID]*simplestore.SimpleStore
 	segmentStoresMutex sync.Mutex
+	stopped            bool
 }
 
 func NewSimpleStorePool(kv kv.KV) *SimpleStorePool {
@@ -30,7 +31,23 @@ func (r *SimpleStorePool) GetStoreForSegment(segmentId uuid.UUID) *simplestore.S
 			r.kv,
 			segmentId.String(),
 		)
+
+		// Once the pool is stopped, the stores it returns are stopped too.
+		if r.stopped {
+			r.segmentStores[segmentId].Stop()
+		}
 	}
 
 	return r.segmentStores[segmentId]
 }
+
+// Stop stops the segment stores, so that their writes fail with `simplestore.StoreIsStoppedErr`.
+func (r *SimpleStorePool) Stop() {
+	r.segmentStoresMutex.Lock()
+	defer r.segmentStoresMutex.Unlock()
+
+	r.stopped = true
+	for _, store := range r.segmentStores {
+		store.Stop()
+	}
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"github.com/google/uuid"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// StreamPositionCacheSize is the maximum number of stream heads kept in memory by each node.
+var StreamPositionCacheSize = 10000
+
+type cachedStreamHead struct {
+	// The segment in which the stream was written into when the position was cached. If the
+	// topology changes and the stream has to be written into another segment, the position
+	// can't be trusted anymore.
+	segmentId uuid.UUID
+	position  int64
+}
+
+// streamPositionCache keeps the head position of the recently written streams, so that
+// appending to a hot stream doesn't require walking its segments to find its position.
+// A cached position is only a hint, as other nodes might have written in the stream since: a
+// write relying on a stale position fails on its stream condition and the entry is invalidated,
+// and a user condition contradicting the cached position is checked against the KV store.
+type streamPositionCache struct {
+	heads *lru.Cache
+}
+
+func newStreamPositionCache(size int) *streamPositionCache {
+	heads, err := lru.New(size)
+	if err != nil {
+		panic(err)
+	}
+
+	return &streamPositionCache{heads: heads}
+}
+
+func (c *streamPositionCache) Get(stream string, segmentId uuid.UUID) (int64, bool) {
+	value, found := c.heads.Get(stream)
+	if !found {
+		streamPositionCacheLookups.WithLabelValues("miss").Inc()
+		return 0, false
+	}
+
+	head := value.(cachedStreamHead)
+	if head.segmentId != segmentId {
+		c.heads.Remove(stream)
+
+		streamPositionCacheLookups.WithLabelValues("miss").Inc()
+		return 0, false
+	}
+
+	streamPositionCacheLookups.WithLabelValues("hit").Inc()
+	return head.position, true
+}
+
+func (c *streamPositionCache) Set(stream string, segmentId uuid.UUID, position int64) {
+	c.heads.Add(stream, cachedStreamHead{
+		segmentId: segmentId,
+		position:  position,
+	})
+}
+
+func (c *streamPositionCache) Invalidate(stream string) {
+	c.heads.Remove(stream)
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_StreamPositionCache(t *testing.T) {
+	segmentId := uuid.New()
+
+	t.Run("returns the cached position for the same segment", func(t *testing.T) {
+		c := newStreamPositionCache(10)
+		c.Set("foo", segmentId, 12)
+
+		position, found := c.Get("foo", segmentId)
+		assert.True(t, found)
+		assert.Equal(t, int64(12), position)
+	})
+
+	t.Run("misses when the stream is written into another segment", func(t *testing.T) {
+		c := newStreamPositionCache(10)
+		c.Set("foo", segmentId, 12)
+
+		_, found := c.Get("foo", uuid.New())
+		assert.False(t, found)
+
+		_, found = c.Get("foo", segmentId)
+		assert.False(t, found)
+	})
+
+	t.Run("misses once invalidated", func(t *testing.T) {
+		c := newStreamPositionCache(10)
+		c.Set("foo", segmentId, 12)
+		c.Invalidate("foo")
+
+		_, found := c.Get("foo", segmentId)
+		assert.False(t, found)
+	})
+
+	t.Run("is bounded", func(t *testing.T) {
+		c := newStreamPositionCache(2)
+		c.Set("foo", segmentId, 1)
+		c.Set("bar", segmentId, 2)
+		c.Set("baz", segmentId, 3)
+
+		_, found := c.Get("foo", segmentId)
+		assert.False(t, found)
+		_, found = c.Get("baz", segmentId)
+		assert.True(t, found)
+	})
+}
@@ -0,0 +1,36 @@
+package store
+
+import (
+	"context"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/simplestore"
+)
+
+// PrefixReader is a `livetail.Reader` of the events of the streams matching a prefix. Its
+// positions are the cursors of the `EventSource`.
+type PrefixReader struct {
+	source *EventSource
+	prefix string
+}
+
+func NewPrefixReader(store *Store, prefix string) *PrefixReader {
+	return &PrefixReader{
+		source: NewEventSource(store),
+		prefix: prefix,
+	}
+}
+
+func (r *PrefixReader) Read(ctx context.Context, startingPosition string, ch chan simplestore.ReadItem) {
+	defer close(ch)
+
+	intermediaryCh := make(chan eskit.SourceItem)
+	go r.source.Query(ctx, r.prefix, startingPosition, intermediaryCh)
+
+	for item := range intermediaryCh {
+		ch <- simplestore.ReadItem{
+			EventInStream: item.EventInStream,
+			Cursor:        item.Cursor,
+			Error:         item.Error,
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/sroze/fossil/eskit"
+	"github.com/sroze/fossil/eskit/codec"
+	"github.com/sroze/fossil/livetail"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/store/segments"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_PrefixReader(t *testing.T) {
+	withFreshStore(t, func(ctx testingContext) {
+		_, err := ctx.store.topologyManager.Create(segments.NewSegment(
+			segments.NewPrefixRange("foo"),
+		))
+		assert.Nil(t, err)
+
+		writes, _ := simplestore.GenerateEventWriteRequests(3, 1, "foo/")
+		_, err = ctx.store.Write(context.Background(), writes)
+		assert.Nil(t, err)
+
+		reader := NewPrefixReader(ctx.store, "foo/")
+		read := func(startingPosition string) []simplestore.ReadItem {
+			ch := make(chan simplestore.ReadItem)
+			go reader.Read(context.Background(), startingPosition, ch)
+
+			var items []simplestore.ReadItem
+			for item := range ch {
+				assert.Nil(t, item.Error)
+				items = append(items, item)
+			}
+
+			return items
+		}
+
+		t.Run("items carry the cursor to resume from", func(t *testing.T) {
+			items := read("0")
+			assert.Equal(t, 3, len(items))
+			assert.NotEqual(t, "", items[0].Cursor)
+
+			resumedItems := read(items[0].Cursor)
+			assert.Equal(t, items[1:], resumedItems)
+		})
+
+		t.Run("a checkpointed projection resumes from its checkpoint", func(t *testing.T) {
+			prefix := "foo/" + uuid.NewString() + "/"
+			c := codec.NewGobCodec(projectedEvent{})
+			writeEvents := func(stream string, strings ...string) {
+				for _, s := range strings {
+					event, err := c.Serialize(projectedEvent{S: s})
+					assert.Nil(t, err)
+
+					_, err = ctx.store.Write(context.Background(), []simplestore.AppendToStream{
+						{Stream: stream, Events: []simplestore.Event{event}},
+					})
+					assert.Nil(t, err)
+				}
+			}
+
+			var collected []string
+			project := func(checkpoints eskit.CheckpointStore) {
+				p := eskit.NewCheckpointedProjection(prefix, livetail.NewLiveTail(NewPrefixReader(ctx.store, prefix)), c, func(ctx context.Context, event eskit.EventInStream) error {
+					collected = append(collected, event.Event.(*projectedEvent).S)
+
+					return nil
+				}, checkpoints)
+				assert.Nil(t, p.Start(context.Background()))
+				assert.Nil(t, p.WaitEndOfStream())
+				assert.Nil(t, p.Stop())
+			}
+
+			checkpoints := eskit.NewFileCheckpointStore(t.TempDir())
+			writeEvents(prefix+"1", "a", "b")
+			writeEvents(prefix+"2", "c")
+
+			project(checkpoints)
+			assert.Equal(t, []string{"a", "b", "c"}, collected)
+
+			writeEvents(prefix+"1", "d")
+
+			collected = nil
+			project(checkpoints)
+			assert.Equal(t, []string{"d"}, collected)
+		})
+	})
+}
+
+type projectedEvent struct {
+	S string
+}
@@ -7,21 +7,31 @@ import (
 	"github.com/heimdalr/dag"
 	"github.com/sroze/fossil/simplestore"
 	"github.com/sroze/fossil/store/topology"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TODO: implement batching?
 // TODO: it should always return a cursor, even if the results are empty, so that the client can keep track of the position
 // TODO: and don't force us to read many empty segments again.
 func (s *Store) Query(ctx context.Context, prefix string, positionCursor PositionCursor, ch chan QueryItem) {
+	ctx, span := tracer.Start(ctx, "store.Query", trace.WithAttributes(
+		tracing.PrefixKey.String(prefix),
+	))
+
 	segmentsRelevantToPrefix, err := s.topologyManager.GetSegmentsToReadFromPrefix(prefix)
 	if err != nil {
-		ch <- QueryItem{Error: fmt.Errorf("could not get segments to read from: %w", err)}
+		err = fmt.Errorf("could not get segments to read from: %w", err)
+		tracing.End(span, err)
+		ch <- QueryItem{Error: err}
 		return
 	}
 
 	startingPosition, err := topology.NewPositionFromSerialized(string(positionCursor))
 	if err != nil {
-		ch <- QueryItem{Error: fmt.Errorf("could not deserialize position cursor: %w", err)}
+		err = fmt.Errorf("could not deserialize position cursor: %w", err)
+		tracing.End(span, err)
+		ch <- QueryItem{Error: err}
 		return
 	}
 
@@ -33,10 +43,20 @@ func (s *Store) Query(ctx context.Context, prefix string, positionCursor Positio
 	go func(target chan QueryItem, cursor *topology.Position) {
 		defer close(target)
 
+		// The aggregator is the last to finish, so it ends the span.
+		events := 0
+		defer func() {
+			eventsRead.WithLabelValues("query").Observe(float64(events))
+			span.SetAttributes(tracing.EventCountKey.Int(events))
+			span.End()
+		}()
+
 		for event := range eventAggregator {
 			err := cursor.AdvanceTo(segmentsToRead, event.segmentId, event.segmentPosition+1)
 			if err != nil {
-				target <- QueryItem{Error: fmt.Errorf("unable to advance cursor: %w", err)}
+				err = fmt.Errorf("unable to advance cursor: %w", err)
+				tracing.RecordError(span, err)
+				target <- QueryItem{Error: err}
 				return
 			}
 
@@ -51,6 +71,7 @@ func (s *Store) Query(ctx context.Context, prefix string, positionCursor Positio
 				},
 				Position: (*PositionCursor)(&cursorAsString),
 			}
+			events++
 		}
 	}(ch, startingPosition.Clone())
 
@@ -62,6 +83,7 @@ func (s *Store) Query(ctx context.Context, prefix string, positionCursor Positio
 	)
 
 	if err != nil {
+		tracing.RecordError(span, err)
 		ch <- QueryItem{Error: err}
 	}
 
@@ -6,18 +6,27 @@ import (
 	"github.com/heimdalr/dag"
 	"github.com/sroze/fossil/simplestore"
 	"github.com/sroze/fossil/store/topology"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"sync"
 )
 
 func (s *Store) Read(ctx context.Context, stream string, ch chan simplestore.ReadItem, options simplestore.ReadOptions) {
+	ctx, span := tracer.Start(ctx, "store.Read", trace.WithAttributes(
+		tracing.StreamKey.String(stream),
+		attribute.Int64("fossil.starting_position", options.StartingPosition),
+		attribute.Bool("fossil.backwards", options.Backwards),
+	))
+	defer span.End()
+
 	segments, err := s.topologyManager.GetSegmentsToReadFromStream(stream)
 	if err != nil {
+		tracing.End(span, err)
 		ch <- simplestore.ReadItem{Error: err}
 		return
 	}
 
-	// TODO: trim the list of segments through a bloom filter on the closed segments.
-
 	// We have a 'centralised' aggregator that receives events from the segments and sends them to the channel.
 	// This is where we handle the limit.
 	walkerCtx, cancelWalk := context.WithCancel(ctx)
@@ -29,11 +38,24 @@ func (s *Store) Read(ctx context.Context, stream string, ch chan simplestore.Rea
 		defer close(ch)
 		defer wg.Done()
 
-		count := 0
+		count, events := 0, 0
+		defer func() {
+			eventsRead.WithLabelValues("read").Observe(float64(events))
+			span.SetAttributes(tracing.EventCountKey.Int(events))
+		}()
+
 		for item := range aggregator {
 			ch <- item
 			count++
 
+			if item.EventInStream != nil {
+				events++
+			}
+
+			if item.Error != nil {
+				tracing.RecordError(span, item.Error)
+			}
+
 			if options.Limit > 0 && count >= options.Limit {
 				cancelWalk()
 				break
@@ -44,34 +66,70 @@ func (s *Store) Read(ctx context.Context, stream string, ch chan simplestore.Rea
 	// Walk the DAG forward to provide an ordered view of the events.
 	walker := func(segmentId dag.IDInterface) error {
 		segmentStore := s.pool.GetStoreForSegment(uuid.MustParse(segmentId.ID()))
+
+		// Closed segments (i.e. the ones with children) have a bloom filter of their streams, which
+		// allows us to skip the segments that definitely do not contain the stream.
+		checkedBloomFilter := false
+		if children, err := segments.GetChildren(segmentId.ID()); err == nil && len(children) > 0 {
+			mightContainStream, err := segmentStore.MightContainStream(ctx, stream)
+			if err != nil {
+				return err
+			}
+
+			if !mightContainStream {
+				bloomFilterChecks.WithLabelValues("skipped").Inc()
+				return nil
+			}
+
+			bloomFilterChecks.WithLabelValues("read").Inc()
+			checkedBloomFilter = true
+		}
+
 		segmentCh := make(chan simplestore.ReadItem)
 		go segmentStore.Read(ctx, stream, segmentCh, options)
 
+		eventCount := 0
 		for item := range segmentCh {
-			aggregator <- item
+			// The end of the stream in a segment is not the end of the stream.
+			if item.EndOfStreamSignal != nil {
+				continue
+			}
 
+			if item.EventInStream != nil {
+				eventCount++
+			}
+
+			// Once the limit is reached, the rest of the segment is drained without being sent.
 			select {
 			case <-walkerCtx.Done():
-				break
-			default:
+			case aggregator <- item:
 			}
 		}
 
+		// We can only tell it was a false positive if we read the segment entirely.
+		if checkedBloomFilter && eventCount == 0 && options.StartingPosition == 0 && walkerCtx.Err() == nil {
+			bloomFilterFalsePositives.Inc()
+		}
+
 		return nil
 	}
 
-	// TODO: handle `limit`.
 	if options.Backwards {
 		err = topology.WalkBackwardsDag(walkerCtx, segments, walker)
 	} else {
 		err = topology.WalkForwardDag(segments, walker)
 	}
 
-	close(aggregator)
-	wg.Wait()
-
+	// The error goes through the aggregator, which closes the channel. It is not relevant
+	// anymore once the limit is reached.
 	if err != nil {
-		ch <- simplestore.ReadItem{Error: err}
-		return
+		select {
+		case <-walkerCtx.Done():
+		case aggregator <- simplestore.ReadItem{Error: err}:
+		}
 	}
+
+	close(aggregator)
+	wg.Wait()
+	cancelWalk()
 }
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sroze/fossil/simplestore"
+	"math/rand"
+	"time"
+)
+
+type RetryPolicy struct {
+	// The maximum number of times a write is retried.
+	MaxRetries int
+
+	// The backoff before the first retry of a concurrent write on a segment. It doubles
+	// for each subsequent retry, up to `MaxBackoff`, and is randomised (full jitter).
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// The maximum time to wait for the topology to be refreshed, before retrying a write in a
+	// segment that turned out to be closed.
+	TopologyRefreshTimeout time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     5,
+	InitialBackoff: 5 * time.Millisecond,
+	MaxBackoff:     200 * time.Millisecond,
+
+	TopologyRefreshTimeout: 1 * time.Second,
+}
+
+// RetriedWriteErr is returned when a write failed after having been retried.
+type RetriedWriteErr struct {
+	Retries int
+	Err     error
+}
+
+func (e RetriedWriteErr) Error() string {
+	return fmt.Sprintf("write failed after %d retries: %s", e.Retries, e.Err)
+}
+
+func (e RetriedWriteErr) Unwrap() error {
+	return e.Err
+}
+
+type retryReason string
+
+const (
+	noRetry              retryReason = ""
+	retryStreamCondition retryReason = "stream_condition"
+	retryConcurrentWrite retryReason = "segment_concurrent_write"
+	retryClosedSegment   retryReason = "segment_closed"
+)
+
+// retryReasonFor returns why the write should be retried, if it should.
+func retryReasonFor(commands []simplestore.AppendToStream, err error) retryReason {
+	var conditionFailed simplestore.StreamConditionFailed
+	if errors.As(err, &conditionFailed) {
+		for _, command := range commands {
+			if command.Stream == conditionFailed.Stream && command.Condition != nil {
+				// The condition was set by the user: retrying would fail again.
+				return noRetry
+			}
+		}
+
+		// Given the current implementation, this means that another writer has written in the stream too, because
+		// we fetch the positions in the application before writing.
+		return retryStreamCondition
+	}
+
+	if errors.Is(err, simplestore.SegmentConcurrentWriteErr) {
+		return retryConcurrentWrite
+	}
+
+	if errors.Is(err, simplestore.StoreIsClosedErr{}) {
+		return retryClosedSegment
+	}
+
+	return noRetry
+}
+
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
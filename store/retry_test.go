@@ -0,0 +1,60 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"github.com/sroze/fossil/simplestore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_RetryReason(t *testing.T) {
+	commands := []simplestore.AppendToStream{
+		{Stream: "foo"},
+		{Stream: "bar", Condition: &simplestore.AppendCondition{WriteAtPosition: 2}},
+	}
+
+	t.Run("does not retry user-set conditions", func(t *testing.T) {
+		assert.Equal(t, noRetry, retryReasonFor(commands, simplestore.StreamConditionFailed{Stream: "bar"}))
+	})
+
+	t.Run("retries conditions set from the fetched stream position", func(t *testing.T) {
+		assert.Equal(t, retryStreamCondition, retryReasonFor(commands, simplestore.StreamConditionFailed{Stream: "foo"}))
+	})
+
+	t.Run("retries concurrent writes on the segment", func(t *testing.T) {
+		assert.Equal(t, retryConcurrentWrite, retryReasonFor(commands, simplestore.SegmentConcurrentWriteErr))
+	})
+
+	t.Run("retries writes on closed segments", func(t *testing.T) {
+		err := fmt.Errorf("failed to get incremented segment position: %w", simplestore.StoreIsClosedErr{})
+		assert.Equal(t, retryClosedSegment, retryReasonFor(commands, err))
+	})
+
+	t.Run("does not retry other errors", func(t *testing.T) {
+		assert.Equal(t, noRetry, retryReasonFor(commands, errors.New("boom")))
+	})
+}
+
+func Test_RetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     40 * time.Millisecond,
+	}
+
+	for retry := 1; retry <= 10; retry++ {
+		backoff := p.backoff(retry)
+		assert.GreaterOrEqual(t, backoff, time.Duration(0))
+		assert.Less(t, backoff, p.MaxBackoff)
+	}
+
+	assert.Less(t, p.backoff(1), p.InitialBackoff)
+}
+
+func Test_RetriedWriteErr(t *testing.T) {
+	err := RetriedWriteErr{Retries: 3, Err: simplestore.SegmentConcurrentWriteErr}
+
+	assert.True(t, errors.Is(err, simplestore.SegmentConcurrentWriteErr))
+	assert.Equal(t, "write failed after 3 retries: concurrent write on segment", err.Error())
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"fmt"
 	"github.com/EagleChen/mapmutex"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/kv"
@@ -14,20 +15,26 @@ type Store struct {
 	id              uuid.UUID
 	topologyManager *topology.Manager
 	kv              kv.KV
+	ss              *simplestore.SimpleStore
 	pool            *pool.SimpleStorePool
 	segmentLock     *mapmutex.Mutex
+	streamPositions *streamPositionCache
+	retryPolicy     RetryPolicy
 }
 
 func NewStore(
 	kv kv.KV,
 	id uuid.UUID,
 ) *Store {
+	// The topology manager closes segments through the same segment stores as the writes, so
+	// that they are serialized by the same position locks.
 	ss := simplestore.NewStore(kv, id.String())
+	segmentStores := pool.NewSimpleStorePool(kv)
 	topologyManager := topology.NewManager(
 		ss,
 		"$system",
 		RootCodec,
-		pool.NewSimpleStorePool(kv),
+		segmentStores,
 		kv,
 	)
 
@@ -35,24 +42,37 @@ func NewStore(
 		id:              id,
 		topologyManager: topologyManager,
 		kv:              kv,
-		pool:            pool.NewSimpleStorePool(kv),
+		ss:              ss,
+		pool:            segmentStores,
 		segmentLock:     mapmutex.NewMapMutex(),
+		streamPositions: newStreamPositionCache(StreamPositionCacheSize),
+		retryPolicy:     DefaultRetryPolicy,
 	}
 }
 
+// SetRetryPolicy configures how writes are retried when they fail because of concurrent writers.
+func (s *Store) SetRetryPolicy(policy RetryPolicy) {
+	s.retryPolicy = policy
+}
+
 func (s *Store) Start() error {
 	err := s.topologyManager.Start()
 	if err != nil {
 		return err
 	}
 
-	s.topologyManager.WaitReady()
+	err = s.topologyManager.WaitReady()
+	if err != nil {
+		return fmt.Errorf("could not load the topology: %w", err)
+	}
 
 	return nil
 }
 
 func (s *Store) Stop() {
 	s.topologyManager.Stop()
+	s.ss.Stop()
+	s.pool.Stop()
 }
 
 func (s *Store) GetTopologyManager() *topology.Manager {
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/heimdalr/dag"
 	"github.com/sroze/fossil/store/segments"
+	"golang.org/x/exp/maps"
 	"reflect"
+	"sort"
 )
 
 type NoSegmentToWriteIntoError struct {
@@ -14,6 +16,14 @@ func (e NoSegmentToWriteIntoError) Error() string {
 	return "no segment to write into"
 }
 
+type SegmentNotFoundError struct {
+	SegmentId string
+}
+
+func (e SegmentNotFoundError) Error() string {
+	return fmt.Sprintf("segment %s not found", e.SegmentId)
+}
+
 type GraphState struct {
 	// `dag` is a directed acyclic graph (DAG) implementation.
 	d *dag.DAG
@@ -32,7 +42,11 @@ func initialGraphState() GraphState {
 	}
 }
 
+// EvolveGraphState returns a new state with the event applied. The given state is not modified,
+// so that it can be read concurrently.
 func EvolveGraphState(state GraphState, event interface{}) GraphState {
+	state = state.clone()
+
 	switch e := event.(type) {
 	case *SegmentCreatedEvent:
 		state.segments[e.Segment.ID()] = e.Segment
@@ -58,6 +72,23 @@ func EvolveGraphState(state GraphState, event interface{}) GraphState {
 	return state
 }
 
+func (g GraphState) clone() GraphState {
+	d, err := g.d.Copy()
+	if err != nil {
+		panic(err)
+	}
+
+	s := make(map[string]segments.Segment, len(g.segments))
+	for id, segment := range g.segments {
+		s[id] = segment
+	}
+
+	return GraphState{
+		d:        d,
+		segments: s,
+	}
+}
+
 func (g GraphState) GetSegmentToWriteInto(stream string) (segments.Segment, error) {
 	leaves := g.d.GetLeaves()
 	for _, l := range leaves {
@@ -70,6 +101,50 @@ func (g GraphState) GetSegmentToWriteInto(stream string) (segments.Segment, erro
 	return segments.Segment{}, NoSegmentToWriteIntoError{}
 }
 
+// HasWritableSegment returns true when at least one segment can be written into.
+func (g GraphState) HasWritableSegment() bool {
+	return len(g.d.GetLeaves()) > 0
+}
+
+// SegmentCount returns the number of segments, including the closed ones.
+func (g GraphState) SegmentCount() int {
+	return len(g.segments)
+}
+
+// Depth returns the number of segments on the longest path from a root to a leaf: how many
+// generations of segments a full read goes through.
+func (g GraphState) Depth() int {
+	depths := make(map[string]int)
+
+	var depthOf func(id string) int
+	depthOf = func(id string) int {
+		if depth, known := depths[id]; known {
+			return depth
+		}
+
+		depth := 0
+		children, _ := g.d.GetChildren(id)
+		for childId := range children {
+			if childDepth := depthOf(childId); childDepth > depth {
+				depth = childDepth
+			}
+		}
+
+		depths[id] = depth + 1
+
+		return depth + 1
+	}
+
+	depth := 0
+	for rootId := range g.d.GetRoots() {
+		if rootDepth := depthOf(rootId); rootDepth > depth {
+			depth = rootDepth
+		}
+	}
+
+	return depth
+}
+
 func (g GraphState) GetSegmentsToReadFromPrefix(streamPrefix string) (*dag.DAG, error) {
 	return FilterForwardDag(g.d, func(v dag.IDInterface) FilterResult {
 		segment := g.segments[v.(segmentInDag).Id]
@@ -103,6 +178,80 @@ func (g GraphState) GetSegmentsToReadFromStream(stream string) (*dag.DAG, error)
 	}), nil
 }
 
+// SegmentNode is a segment, with the ids of its parents and children in a graph.
+type SegmentNode struct {
+	Segment  segments.Segment
+	Parents  []string
+	Children []string
+}
+
+// GetSegments returns all the segments of the topology, ordered by id.
+func (g GraphState) GetSegments() []SegmentNode {
+	return g.nodesOf(g.d)
+}
+
+// StreamLocation is where a stream is written and read.
+type StreamLocation struct {
+	// WriteSegment is the segment the stream's events are written into, nil when there is none.
+	WriteSegment *segments.Segment
+
+	// ReadSegments are the segments the stream is read from, with their edges in the reading DAG.
+	ReadSegments []SegmentNode
+}
+
+func (g GraphState) GetStreamLocation(stream string) (StreamLocation, error) {
+	location := StreamLocation{}
+	writeSegment, err := g.GetSegmentToWriteInto(stream)
+	if err == nil {
+		location.WriteSegment = &writeSegment
+	} else if _, isNotFound := err.(NoSegmentToWriteIntoError); !isNotFound {
+		return location, err
+	}
+
+	d, err := g.GetSegmentsToReadFromStream(stream)
+	if err != nil {
+		return location, err
+	}
+
+	location.ReadSegments = g.nodesOf(d)
+
+	return location, nil
+}
+
+func (g GraphState) nodesOf(d *dag.DAG) []SegmentNode {
+	vertices := d.GetVertices()
+	ids := maps.Keys(vertices)
+	sort.Strings(ids)
+
+	nodes := make([]SegmentNode, len(ids))
+	for i, id := range ids {
+		parents, err := d.GetParents(id)
+		if err != nil {
+			panic(err)
+		}
+
+		children, err := d.GetChildren(id)
+		if err != nil {
+			panic(err)
+		}
+
+		nodes[i] = SegmentNode{
+			Segment:  g.segments[id],
+			Parents:  sortedKeys(parents),
+			Children: sortedKeys(children),
+		}
+	}
+
+	return nodes
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+
+	return keys
+}
+
 func addVertexOrPanic(d *dag.DAG, v dag.IDInterface) {
 	_, err := d.AddVertex(v)
 	if err != nil {
@@ -91,4 +91,82 @@ func Test_Graph(t *testing.T) {
 		assert.True(t, descendants[3] == iAndiAndj[0].ID() || descendants[3] == iAndiAndj[1].ID() || descendants[3] == iAndiAndj[2].ID())
 		assert.True(t, descendants[4] == iAndiAndj[0].ID() || descendants[4] == iAndiAndj[1].ID() || descendants[4] == iAndiAndj[2].ID())
 	})
+
+	t.Run("evolving does not modify the previous state", func(t *testing.T) {
+		a := segments.NewSegment(segments.NewPrefixRange("f"))
+		b := a.Replacement()
+
+		previous := EvolveGraphState(initialGraphState(), &SegmentCreatedEvent{Segment: a})
+		next := EvolveGraphState(previous, &SegmentReplacedEvent{SegmentId: a.Id, ReplacedBy: b})
+
+		s, err := previous.GetSegmentToWriteInto("foo")
+		assert.Nil(t, err)
+		assert.Equal(t, a, s)
+		assert.Nil(t, previous.GetSegmentById(b.ID()))
+
+		s, err = next.GetSegmentToWriteInto("foo")
+		assert.Nil(t, err)
+		assert.Equal(t, b, s)
+	})
+}
+
+func Test_Graph_Segments(t *testing.T) {
+	// a ('foo/') --> b --> c (#1/2)
+	//                  \-> d (#2/2)
+	// e ('bar/')
+	a := segments.NewSegment(segments.NewPrefixRange("foo/"))
+	b := a.Replacement()
+	cAndd := b.Split(2)
+	e := segments.NewSegment(segments.NewPrefixRange("bar/"))
+
+	g := initialGraphState()
+	g = EvolveGraphState(g, &SegmentCreatedEvent{Segment: a})
+	g = EvolveGraphState(g, &SegmentReplacedEvent{SegmentId: a.Id, ReplacedBy: b})
+	g = EvolveGraphState(g, &SegmentSplitEvent{SegmentId: b.Id, Into: cAndd})
+	g = EvolveGraphState(g, &SegmentCreatedEvent{Segment: e})
+
+	nodeOf := func(nodes []SegmentNode, segment segments.Segment) SegmentNode {
+		for _, node := range nodes {
+			if node.Segment.Id == segment.Id {
+				return node
+			}
+		}
+
+		t.Fatalf("segment %s not found", segment.ID())
+		return SegmentNode{}
+	}
+
+	t.Run("lists the segments with their parents and children", func(t *testing.T) {
+		nodes := g.GetSegments()
+		assert.Equal(t, 5, len(nodes))
+
+		assert.Equal(t, []string{b.ID()}, nodeOf(nodes, a).Children)
+		assert.Equal(t, []string{a.ID()}, nodeOf(nodes, b).Parents)
+		assert.ElementsMatch(t, []string{cAndd[0].ID(), cAndd[1].ID()}, nodeOf(nodes, b).Children)
+		assert.Equal(t, []string{}, nodeOf(nodes, e).Children)
+	})
+
+	t.Run("locates a stream", func(t *testing.T) {
+		location, err := g.GetStreamLocation("foo/123")
+		assert.Nil(t, err)
+		assert.Contains(t, cAndd, *location.WriteSegment)
+
+		// The reading DAG goes through the segment written into.
+		assert.Equal(t, 3, len(location.ReadSegments))
+		assert.Equal(t, []string{location.WriteSegment.ID()}, nodeOf(location.ReadSegments, b).Children)
+	})
+
+	t.Run("locates a stream without segment to write into", func(t *testing.T) {
+		location, err := g.GetStreamLocation("baz/123")
+		assert.Nil(t, err)
+		assert.Nil(t, location.WriteSegment)
+		assert.Equal(t, 0, len(location.ReadSegments))
+	})
+
+	t.Run("measures the segments and the depth of the DAG", func(t *testing.T) {
+		assert.Equal(t, 5, g.SegmentCount())
+		assert.Equal(t, 3, g.Depth())
+		assert.True(t, g.HasWritableSegment())
+		assert.False(t, initialGraphState().HasWritableSegment())
+	})
 }
@@ -3,6 +3,7 @@ package topology
 import (
 	"context"
 	"fmt"
+	"github.com/google/uuid"
 	"github.com/heimdalr/dag"
 	"github.com/sroze/fossil/eskit"
 	"github.com/sroze/fossil/eskit/codec"
@@ -11,6 +12,7 @@ import (
 	"github.com/sroze/fossil/simplestore"
 	"github.com/sroze/fossil/store/pool"
 	"github.com/sroze/fossil/store/segments"
+	"strconv"
 )
 
 type Manager struct {
@@ -23,6 +25,8 @@ type Manager struct {
 	kv                   kv.KV
 }
 
+// NewManager returns the manager of the topology stored in the stream of `ss`. The simple store
+// and the pool remain owned by the caller, which stops them.
 func NewManager(
 	ss *simplestore.SimpleStore,
 	stream string,
@@ -42,7 +46,7 @@ func NewManager(
 			tail,
 			codec,
 			initialGraphState(),
-			EvolveGraphState,
+			evolveAndMeasure,
 		),
 		pool: pool,
 	}
@@ -80,27 +84,74 @@ func (m *Manager) Create(s segments.Segment) (*segments.Segment, error) {
 }
 
 func (m *Manager) Split(segmentId string, chunkCount int) ([]segments.Segment, error) {
-	position := m.topologySubscription.GetPosition()
+	if chunkCount < 1 {
+		return nil, fmt.Errorf("a segment must be split in at least 1 chunk, got %d", chunkCount)
+	}
 
-	segment := m.topologySubscription.GetState().GetSegmentById(segmentId)
-	if segment == nil {
-		return nil, fmt.Errorf("segment %s not found", segmentId)
+	var splitSegmentParts []segments.Segment
+	err := m.closeSegment(segmentId, func(segment *segments.Segment) interface{} {
+		splitSegmentParts = segment.Split(chunkCount)
+
+		return SegmentSplitEvent{
+			SegmentId: segment.Id,
+			Into:      splitSegmentParts,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return splitSegmentParts, nil
+}
+
+// Replace closes the segment, and replaces it with a new segment of the same range.
+func (m *Manager) Replace(segmentId string) (*segments.Segment, error) {
+	var replacement segments.Segment
+	err := m.closeSegment(segmentId, func(segment *segments.Segment) interface{} {
+		replacement = segment.Replacement()
+
+		return SegmentReplacedEvent{
+			SegmentId:  segment.Id,
+			ReplacedBy: replacement,
+		}
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	splitSegmentParts := segment.Split(chunkCount)
+	return &replacement, nil
+}
+
+// closeSegment closes the segment and records the topology event, built from the closed segment,
+// in the same transaction.
+func (m *Manager) closeSegment(segmentId string, eventFor func(segment *segments.Segment) interface{}) error {
+	var position int64
+	var segment *segments.Segment
+	var isOpen bool
+	m.topologySubscription.ReadState(func(state GraphState, p int64) {
+		position = p
+		segment = state.GetSegmentById(segmentId)
+		if segment != nil {
+			children, err := state.d.GetChildren(segmentId)
+			isOpen = err == nil && len(children) == 0
+		}
+	})
+
+	if segment == nil {
+		return SegmentNotFoundError{SegmentId: segmentId}
+	} else if !isOpen {
+		return fmt.Errorf("segment %s is already closed", segmentId)
+	}
 
 	previousSegmentsStore := m.pool.GetStoreForSegment(segment.Id)
 	closeWrites, err := previousSegmentsStore.PrepareCloseKvWrites(context.Background())
 	if err != nil {
-		return nil, fmt.Errorf("could not prepare writes to close the store: %w", err)
+		return fmt.Errorf("could not prepare writes to close the store: %w", err)
 	}
 
-	event, err := m.codec.Serialize(SegmentSplitEvent{
-		SegmentId: segment.Id,
-		Into:      splitSegmentParts,
-	})
+	event, err := m.codec.Serialize(eventFor(segment))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	topologyWrites, topologyResults, err := m.ss.PrepareKvWrites(context.Background(), []simplestore.AppendToStream{{
@@ -109,18 +160,21 @@ func (m *Manager) Split(segmentId string, chunkCount int) ([]segments.Segment, e
 		Condition: &simplestore.AppendCondition{WriteAtPosition: position + 1},
 	}})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	kvWrites, unlock, err := m.ss.TransformWritesAndAcquirePositionLock(context.Background(), topologyWrites)
-	defer unlock()
+	kvWrites, lease, err := m.ss.TransformWritesAndAcquirePositionLock(context.Background(), topologyWrites)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	err = m.kv.Write(append(closeWrites, kvWrites...))
+	defer lease.Release()
+
+	err = lease.Do(func() error {
+		return m.kv.Write(lease.Context(), append(closeWrites, kvWrites...))
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// wait for the livetail to be caught up.
@@ -129,7 +183,29 @@ func (m *Manager) Split(segmentId string, chunkCount int) ([]segments.Segment, e
 		topologyResults[0].Position,
 	)
 
-	return splitSegmentParts, nil
+	return nil
+}
+
+// Refresh waits for the topology to be up-to-date with the latest topology changes. It is useful
+// when a segment is known to have been closed by another node.
+func (m *Manager) Refresh(ctx context.Context) error {
+	ch := make(chan simplestore.ReadItem)
+	go m.ss.Read(ctx, m.stream, ch, simplestore.ReadOptions{
+		Backwards: true,
+		Limit:     1,
+	})
+
+	for item := range ch {
+		if item.Error != nil {
+			return fmt.Errorf("could not read topology head: %w", item.Error)
+		}
+
+		if item.EventInStream != nil {
+			m.topologySubscription.WaitForPosition(ctx, item.EventInStream.Position)
+		}
+	}
+
+	return ctx.Err()
 }
 
 func (m *Manager) Start() error {
@@ -138,8 +214,15 @@ func (m *Manager) Start() error {
 	return nil
 }
 
-func (m *Manager) WaitReady() {
-	m.topologySubscription.WaitEndOfStream()
+// WaitReady waits for the topology to be loaded. It returns an error if it could not be.
+func (m *Manager) WaitReady() error {
+	return m.topologySubscription.WaitEndOfStream()
+}
+
+// Health returns the state of the topology subscription, which fails if an event of the
+// topology stream can't be applied.
+func (m *Manager) Health() eskit.Health {
+	return m.topologySubscription.Health()
 }
 
 func (m *Manager) Stop() {
@@ -157,3 +240,69 @@ func (m *Manager) GetSegmentsToReadFromPrefix(streamPrefix string) (*dag.DAG, er
 func (m *Manager) GetSegmentsToReadFromStream(stream string) (*dag.DAG, error) {
 	return m.topologySubscription.GetState().GetSegmentsToReadFromStream(stream)
 }
+
+// GetState returns the current topology.
+func (m *Manager) GetState() GraphState {
+	return m.topologySubscription.GetState()
+}
+
+// GetSegmentHead returns the position of the segment's last event, and whether it is closed.
+func (m *Manager) GetSegmentHead(ctx context.Context, segmentId uuid.UUID) (int64, bool, error) {
+	return m.pool.GetStoreForSegment(segmentId).Head(ctx)
+}
+
+// WatchedEvent is an event of the topology stream.
+type WatchedEvent struct {
+	Position int64
+	Event    interface{}
+	Error    error
+}
+
+// Watch sends the events of the topology stream, from the starting position, until the context
+// is cancelled or an error occurs. It closes the channel once done.
+func (m *Manager) Watch(ctx context.Context, startingPosition int64, ch chan WatchedEvent) {
+	defer close(ch)
+
+	tail := livetail.NewLiveTail(livetail.NewStreamReader(m.ss, m.stream))
+	items := make(chan simplestore.ReadItem)
+	go tail.Start(strconv.FormatInt(startingPosition, 10), items)
+
+	// The channel is drained so that the live tail can stop.
+	defer func() {
+		tail.Stop()
+		for range items {
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case item, more := <-items:
+			if !more {
+				return
+			}
+
+			if item.Error != nil {
+				ch <- WatchedEvent{Error: item.Error}
+				return
+			}
+
+			if item.EventInStream == nil {
+				continue
+			}
+
+			event, err := m.codec.Deserialize(item.EventInStream.Event)
+			if err != nil {
+				ch <- WatchedEvent{Error: fmt.Errorf("could not deserialize topology event: %w", err)}
+				return
+			}
+
+			select {
+			case ch <- WatchedEvent{Position: item.EventInStream.Position, Event: event}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package topology
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	segmentCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fossil_topology_segments",
+		Help: "Number of segments in the topology, including the closed ones.",
+	})
+
+	dagDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "fossil_topology_dag_depth",
+		Help: "Number of segments on the longest path of the topology's DAG, from a root to a leaf.",
+	})
+)
+
+// evolveAndMeasure evolves the graph, and updates the topology's metrics.
+func evolveAndMeasure(state GraphState, event interface{}) GraphState {
+	state = EvolveGraphState(state, event)
+
+	segmentCount.Set(float64(state.SegmentCount()))
+	dagDepth.Set(float64(state.Depth()))
+
+	return state
+}
@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+	"github.com/sroze/fossil/simplestore"
+	"go.opentelemetry.io/otel"
+)
+
+var tracer = otel.Tracer("github.com/sroze/fossil/store")
+
+// TraceContextInEvents records the trace of the writes in the metadata of their events, so that
+// their consumers can relate to it.
+var TraceContextInEvents = false
+
+func withTraceContext(ctx context.Context, commands []simplestore.AppendToStream) []simplestore.AppendToStream {
+	traced := make([]simplestore.AppendToStream, len(commands))
+	for i, command := range commands {
+		events := make([]simplestore.Event, len(command.Events))
+		for j, event := range command.Events {
+			events[j] = simplestore.AddTraceContextToMetadata(ctx, event)
+		}
+
+		command.Events = events
+		traced[i] = command
+	}
+
+	return traced
+}
@@ -7,58 +7,103 @@ import (
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/kv"
 	"github.com/sroze/fossil/simplestore"
+	"github.com/sroze/fossil/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
+	"sort"
+	"time"
 )
 
 func (s *Store) Write(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error) {
-	results, err := s.attemptWrite(ctx, commands)
-	if err != nil {
-		shouldRetry := false
-		if errors.Is(err, simplestore.SegmentConcurrentWriteErr) {
-			shouldRetry = true
+	streams := make([]string, len(commands))
+	for i, command := range commands {
+		streams[i] = command.Stream
+	}
+
+	ctx, span := tracer.Start(ctx, "store.Write", trace.WithAttributes(
+		tracing.StreamsKey.StringSlice(streams),
+		tracing.EventCountKey.Int(countEvents(commands)),
+	))
+
+	if TraceContextInEvents {
+		commands = withTraceContext(ctx, commands)
+	}
+
+	results, err := s.write(ctx, commands)
+	tracing.End(span, err)
+
+	return results, err
+}
+
+func (s *Store) write(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error) {
+	retries := 0
+	for {
+		results, err := s.attemptWrite(ctx, commands)
+		if err == nil {
+			return results, nil
 		}
 
-		_, isStreamConditionFailed := err.(simplestore.StreamConditionFailed)
-		if isStreamConditionFailed {
-			// TODO: if it was a user-set condition, there's not even a point retrying.
-			shouldRetry = true
+		countWriteFailure(err)
+
+		// The cached head positions of these streams might be the reason of the failure.
+		for _, command := range commands {
+			s.streamPositions.Invalidate(command.Stream)
 		}
 
-		if shouldRetry {
-			// Given the current implementation, this means that another writer has written in the stream too, because we currently fetch
-			// the positions in the application before writing. As such, we should retry, within reasonable limits.
-			retryCount := ctx.Value("retryCount")
-			if retryCount == nil {
-				retryCount = 0
+		reason := retryReasonFor(commands, err)
+		if reason == noRetry || retries >= s.retryPolicy.MaxRetries {
+			if retries > 0 {
+				err = RetriedWriteErr{Retries: retries, Err: err}
 			}
 
-			if retryCount.(int) > 5 {
-				return results, fmt.Errorf("failed too many times: %w", err)
+			return results, err
+		}
+
+		retries++
+		writeRetries.WithLabelValues(string(reason)).Inc()
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.String("fossil.retry_reason", string(reason)),
+		))
+
+		switch reason {
+		case retryConcurrentWrite:
+			if sleepErr := sleep(ctx, s.retryPolicy.backoff(retries)); sleepErr != nil {
+				return results, RetriedWriteErr{Retries: retries, Err: sleepErr}
 			}
+		case retryClosedSegment:
+			// Another node has closed the segment: we need the new topology to find where to write.
+			refreshCtx, cancel := context.WithTimeout(ctx, s.retryPolicy.TopologyRefreshTimeout)
+			refreshErr := s.topologyManager.Refresh(refreshCtx)
+			cancel()
 
-			return s.Write(
-				context.WithValue(ctx, "retryCount", retryCount.(int)+1),
-				commands,
-			)
+			if refreshErr != nil {
+				return results, RetriedWriteErr{Retries: retries, Err: errors.Join(
+					err,
+					fmt.Errorf("could not refresh the topology: %w", refreshErr),
+				)}
+			}
 		}
 	}
-
-	return results, err
 }
 
 func (s *Store) attemptWrite(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendResult, error) {
-	preparedCommands, err := s.prepareCommands(commands)
+	preparedCommands, err := s.prepareCommands(ctx, commands)
 	if err != nil {
 		return nil, err
 	}
 
 	// Group commands by segment
 	commandsBySegment := make(map[uuid.UUID]map[int]simplestore.AppendToStream)
+	segmentIds := make([]uuid.UUID, len(preparedCommands))
 	for commandIndex, command := range preparedCommands {
 		segment, err := s.topologyManager.GetSegmentToWriteInto(command.Stream)
 		if err != nil {
 			return nil, err
 		}
 
+		segmentIds[commandIndex] = segment.Id
+
 		if _, exists := commandsBySegment[segment.Id]; !exists {
 			commandsBySegment[segment.Id] = make(map[int]simplestore.AppendToStream)
 		}
@@ -89,20 +134,21 @@ func (s *Store) attemptWrite(ctx context.Context, commands []simplestore.AppendT
 		}
 	}
 
-	// Lock and transform each write then send to KV.
-	var kvWrites []kv.Write
-	for segmentId, segmentWrites := range preparedWritesPerSegment {
-		w, unlock, err := s.pool.GetStoreForSegment(segmentId).TransformWritesAndAcquirePositionLock(ctx, segmentWrites)
-		defer unlock()
-
-		if err != nil {
-			return results, err
+	start := time.Now()
+	if len(preparedWritesPerSegment) == 1 {
+		// Writes within a single segment are grouped with the segment's other concurrent writes.
+		for segmentId, segmentWrites := range preparedWritesPerSegment {
+			err = s.pool.GetStoreForSegment(segmentId).Commit(ctx, segmentWrites)
 		}
+	} else {
+		err = s.commitAcrossSegments(ctx, preparedWritesPerSegment)
+	}
 
-		kvWrites = append(kvWrites, w...)
+	for segmentId, segmentCommands := range commandsBySegment {
+		appendDuration.WithLabelValues(segmentId.String()).Observe(time.Since(start).Seconds())
+		appendBatchSize.WithLabelValues(segmentId.String()).Observe(float64(countEvents(maps.Values(segmentCommands))))
 	}
 
-	err = s.kv.Write(kvWrites)
 	if err != nil {
 		for segmentId, _ := range preparedWritesPerSegment {
 			handled, transformed := s.pool.GetStoreForSegment(segmentId).HandleError(err)
@@ -111,12 +157,62 @@ func (s *Store) attemptWrite(ctx context.Context, commands []simplestore.AppendT
 				break
 			}
 		}
+
+		return results, err
 	}
 
-	return results, err
+	for i, command := range preparedCommands {
+		s.streamPositions.Set(command.Stream, segmentIds[i], results[i].Position)
+	}
+
+	return results, nil
+}
+
+// commitAcrossSegments writes in multiple segments within a single KV transaction, holding the
+// position lock of each of them. Locks are always acquired in the order of the segment ids, so
+// that concurrent writes across the same segments can't deadlock.
+func (s *Store) commitAcrossSegments(ctx context.Context, preparedWritesPerSegment map[uuid.UUID][]simplestore.PreparedWrite) error {
+	segmentIds := maps.Keys(preparedWritesPerSegment)
+	sort.Slice(segmentIds, func(i, j int) bool {
+		return segmentIds[i].String() < segmentIds[j].String()
+	})
+
+	// Lock and transform each write then send to KV.
+	var kvWrites []kv.Write
+	var leases []*simplestore.PositionLease
+	for _, segmentId := range segmentIds {
+		segmentWrites := preparedWritesPerSegment[segmentId]
+		w, lease, err := s.pool.GetStoreForSegment(segmentId).TransformWritesAndAcquirePositionLock(ctx, segmentWrites)
+		if err != nil {
+			return err
+		}
+
+		defer lease.Release()
+
+		// The next leases are derived from this one, so that the write is cancelled when any
+		// of them expires.
+		ctx = lease.Context()
+		kvWrites = append(kvWrites, w...)
+		leases = append(leases, lease)
+	}
+
+	return writeWithLeases(leases, func() error {
+		return s.kv.Write(ctx, kvWrites)
+	})
+}
+
+// writeWithLeases runs the write unless one of the leases has expired.
+func writeWithLeases(leases []*simplestore.PositionLease, write func() error) error {
+	if len(leases) == 0 {
+		return write()
+	}
+
+	return leases[0].Do(func() error {
+		return writeWithLeases(leases[1:], write)
+	})
 }
 
-func (s *Store) prepareCommands(commands []simplestore.AppendToStream) ([]simplestore.AppendToStream, error) {
+func (s *Store) prepareCommands(ctx context.Context, commands []simplestore.AppendToStream) ([]simplestore.AppendToStream, error) {
 	// Validates that we don't have multiple commands for the same stream.
 	commandsByStream := make(map[string][]simplestore.AppendToStream)
 	for _, command := range commands {
@@ -131,53 +227,97 @@ func (s *Store) prepareCommands(commands []simplestore.AppendToStream) ([]simple
 	// segments.
 	preparedCommands := make([]simplestore.AppendToStream, len(commands))
 	for i, cmd := range commands {
-		streamPosition, err := s.fetchStreamPosition(cmd.Stream)
+		streamPosition, cached, err := s.getStreamPosition(ctx, cmd.Stream)
+		if err != nil {
+			return nil, err
+		}
+
+		prepared, err := prepareCommand(cmd, streamPosition)
+		if err != nil && cached {
+			// The cached position is only a hint: another node might have written in the stream
+			// since, so the condition is checked against the actual position before failing.
+			s.streamPositions.Invalidate(cmd.Stream)
+			streamPosition, _, err = s.getStreamPosition(ctx, cmd.Stream)
+			if err != nil {
+				return nil, err
+			}
+
+			prepared, err = prepareCommand(cmd, streamPosition)
+		}
+
 		if err != nil {
 			return nil, err
 		}
 
+		preparedCommands[i] = prepared
+	}
+
+	return preparedCommands, nil
+}
+
+// prepareCommand checks the command's condition against the stream's position, or sets it when
+// there is none.
+func prepareCommand(cmd simplestore.AppendToStream, streamPosition int64) (simplestore.AppendToStream, error) {
+	if cmd.Condition == nil {
 		if streamPosition == -1 {
 			// The stream does not exist yet.
-			if cmd.Condition == nil {
-				cmd.Condition = &simplestore.AppendCondition{
-					StreamIsEmpty: true,
-				}
-			} else if cmd.Condition.WriteAtPosition > 0 {
-				return nil, simplestore.StreamConditionFailed{
-					Stream:                 cmd.Stream,
-					ExpectedStreamPosition: cmd.Condition.WriteAtPosition,
-				}
+			cmd.Condition = &simplestore.AppendCondition{
+				StreamIsEmpty: true,
 			}
 		} else {
-			// The stream exists.
-			if cmd.Condition == nil {
-				// We add the condition, so that regardless of the target segment's situation,
-				// the position is correct across them all.
-				cmd.Condition = &simplestore.AppendCondition{
-					WriteAtPosition: streamPosition + 1,
-				}
-			} else if cmd.Condition.StreamIsEmpty {
-				return nil, simplestore.StreamConditionFailed{
-					Stream:                 cmd.Stream,
-					ExpectedStreamPosition: -1,
-				}
-			} else if cmd.Condition.WriteAtPosition > 0 && cmd.Condition.WriteAtPosition != (streamPosition+1) {
-				return nil, simplestore.StreamConditionFailed{
-					Stream:                 cmd.Stream,
-					ExpectedStreamPosition: cmd.Condition.WriteAtPosition - 1,
-				}
+			// We add the condition, so that regardless of the target segment's situation,
+			// the position is correct across them all.
+			cmd.Condition = &simplestore.AppendCondition{
+				WriteAtPosition: streamPosition + 1,
 			}
 		}
 
-		preparedCommands[i] = cmd
+		return cmd, nil
 	}
 
-	return preparedCommands, nil
+	if cmd.Condition.StreamIsEmpty {
+		// The stream might start at `WriteAtPosition`.
+		if streamPosition != -1 {
+			return cmd, simplestore.StreamConditionFailed{
+				Stream:                 cmd.Stream,
+				ExpectedStreamPosition: -1,
+			}
+		}
+	} else if cmd.Condition.WriteAtPosition != streamPosition+1 {
+		return cmd, simplestore.StreamConditionFailed{
+			Stream:                 cmd.Stream,
+			ExpectedStreamPosition: cmd.Condition.WriteAtPosition - 1,
+		}
+	}
+
+	return cmd, nil
+}
+
+// getStreamPosition returns the position of the stream's head, from the node's cache when possible.
+// The returned boolean is true when the position comes from the cache.
+func (s *Store) getStreamPosition(ctx context.Context, stream string) (int64, bool, error) {
+	segment, err := s.topologyManager.GetSegmentToWriteInto(stream)
+	if err != nil {
+		return -1, false, err
+	}
+
+	if position, found := s.streamPositions.Get(stream, segment.Id); found {
+		return position, true, nil
+	}
+
+	position, err := s.fetchStreamPosition(ctx, stream)
+	if err != nil {
+		return -1, false, err
+	}
+
+	s.streamPositions.Set(stream, segment.Id, position)
+
+	return position, false, nil
 }
 
-func (s *Store) fetchStreamPosition(stream string) (int64, error) {
+func (s *Store) fetchStreamPosition(ctx context.Context, stream string) (int64, error) {
 	ch := make(chan simplestore.ReadItem)
-	go s.Read(context.Background(), stream, ch, simplestore.ReadOptions{
+	go s.Read(ctx, stream, ch, simplestore.ReadOptions{
 		Backwards: true,
 		Limit:     1,
 	})
@@ -193,3 +333,20 @@ func (s *Store) fetchStreamPosition(stream string) (int64, error) {
 
 	return streamHead.EventInStream.Position, nil
 }
+
+func countEvents(commands []simplestore.AppendToStream) int {
+	count := 0
+	for _, command := range commands {
+		count += len(command.Events)
+	}
+
+	return count
+}
+
+func countWriteFailure(err error) {
+	if errors.As(err, &simplestore.StreamConditionFailed{}) {
+		streamConditionFailures.Inc()
+	} else if errors.Is(err, simplestore.SegmentConcurrentWriteErr) {
+		segmentConcurrentWrites.Inc()
+	}
+}
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/sroze/fossil/simplestore"
 	"github.com/sroze/fossil/store/segments"
@@ -53,6 +54,42 @@ func Test_Writer(t *testing.T) {
 			assert.Nil(t, err)
 		})
 
+		t.Run("a conditional write succeeds when another node has written since it cached the stream head", func(t *testing.T) {
+			stream := "foo/" + uuid.NewString()
+			writeAt := func(node *Store, position int64) error {
+				_, err := node.Write(context.Background(), []simplestore.AppendToStream{
+					{
+						Stream: stream,
+						Events: []simplestore.Event{
+							{EventId: uuid.NewString(), EventType: "Foo", Payload: []byte("foo")},
+						},
+						Condition: &simplestore.AppendCondition{
+							WriteAtPosition: position,
+							StreamIsEmpty:   position == 0,
+						},
+					},
+				})
+
+				return err
+			}
+
+			node1 := NewStore(ctx.kv, ctx.store.id)
+			assert.Nil(t, node1.Start())
+			defer node1.Stop()
+			node2 := NewStore(ctx.kv, ctx.store.id)
+			assert.Nil(t, node2.Start())
+			defer node2.Stop()
+
+			// The first node caches the head at position 0, which is stale once the second node wrote.
+			assert.Nil(t, writeAt(node1, 0))
+			assert.Nil(t, writeAt(node2, 1))
+			assert.Nil(t, writeAt(node1, 2))
+
+			// The conditions are still enforced.
+			assert.True(t, errors.As(writeAt(node2, 2), &simplestore.StreamConditionFailed{}))
+			assert.True(t, errors.As(writeAt(node1, 1), &simplestore.StreamConditionFailed{}))
+		})
+
 		t.Run("writes multiple events in a stream at once", func(t *testing.T) {
 			stream := "foo/" + uuid.NewString()
 
@@ -110,6 +147,22 @@ func Test_Writer(t *testing.T) {
 				assert.NotNil(t, err)
 			})
 
+			t.Run("fails on expecting an empty stream with no stream event in the segment", func(t *testing.T) {
+				_, err := ctx.store.Write(context.Background(), []simplestore.AppendToStream{
+					{
+						Stream: stream,
+						Events: []simplestore.Event{
+							{EventId: uuid.NewString(), EventType: "Bar", Payload: []byte("bar")},
+						},
+						Condition: &simplestore.AppendCondition{
+							WriteAtPosition: 0,
+						},
+					},
+				})
+
+				assert.Equal(t, simplestore.StreamConditionFailed{Stream: stream, ExpectedStreamPosition: -1}, err)
+			})
+
 			t.Run("fails on expecting the wrong stream position in the past with no stream event in the segment", func(t *testing.T) {
 				_, err := ctx.store.Write(context.Background(), []simplestore.AppendToStream{
 					{
@@ -254,6 +307,114 @@ func Test_Writer(t *testing.T) {
 		})
 	})
 
+	t.Run("writes fail once the store is stopped", func(t *testing.T) {
+		withFreshStore(t, func(ctx testingContext) {
+			for _, prefix := range []string{"foo", "bar"} {
+				_, err := ctx.store.topologyManager.Create(segments.NewSegment(
+					segments.NewPrefixRange(prefix),
+				))
+				assert.Nil(t, err)
+			}
+
+			s := NewStore(ctx.kv, ctx.store.id)
+			assert.Nil(t, s.Start())
+
+			write := func(stream string) error {
+				_, err := s.Write(context.Background(), []simplestore.AppendToStream{
+					{Stream: stream, Events: []simplestore.Event{
+						{EventId: uuid.NewString(), EventType: "Foo", Payload: []byte("foo")},
+					}},
+				})
+
+				return err
+			}
+
+			assert.Nil(t, write("foo/"+uuid.NewString()))
+			s.Stop()
+
+			// Including in the segments which were not written into before.
+			assert.ErrorIs(t, write("foo/"+uuid.NewString()), simplestore.StoreIsStoppedErr)
+			assert.ErrorIs(t, write("bar/"+uuid.NewString()), simplestore.StoreIsStoppedErr)
+		})
+	})
+
+	t.Run("concurrent writers on several nodes keep the stream positions consistent", func(t *testing.T) {
+		withFreshStore(t, func(ctx testingContext) {
+			_, err := ctx.store.topologyManager.Create(segments.NewSegment(
+				segments.NewPrefixRange("foo"),
+			))
+			assert.Nil(t, err)
+
+			// Each node has its own cache of stream positions, that will be stale as soon as
+			// another node writes in the stream.
+			numberOfNodes := 3
+			numberOfWritesPerNode := 10
+			var nodes []*Store
+			for i := 0; i < numberOfNodes; i++ {
+				node := NewStore(ctx.kv, ctx.store.id)
+				assert.Nil(t, node.Start())
+				defer node.Stop()
+
+				nodes = append(nodes, node)
+			}
+
+			stream := "foo/" + uuid.NewString()
+			positions := make(chan int64, numberOfNodes*numberOfWritesPerNode)
+			wg := sync.WaitGroup{}
+			wg.Add(numberOfNodes)
+			for _, node := range nodes {
+				go func(node *Store) {
+					defer wg.Done()
+
+					for i := 0; i < numberOfWritesPerNode; i++ {
+						r, err := node.Write(context.Background(), []simplestore.AppendToStream{
+							{
+								Stream: stream,
+								Events: []simplestore.Event{
+									{EventId: uuid.NewString(), EventType: "Foo", Payload: []byte("foo")},
+								},
+							},
+						})
+
+						// Writes might fail after too many retries, but must never share a position.
+						if err == nil {
+							positions <- r[0].Position
+						}
+					}
+				}(node)
+			}
+
+			wg.Wait()
+			close(positions)
+
+			writtenPositions := make(map[int64]bool)
+			for position := range positions {
+				assert.False(t, writtenPositions[position], "position %d was returned twice", position)
+				writtenPositions[position] = true
+			}
+
+			// The stream contains exactly the successful writes, at contiguous positions.
+			eventIds := readStreamEventIds(ctx.store, stream, simplestore.ReadOptions{})
+			assert.Equal(t, len(writtenPositions), len(eventIds))
+			for i := 0; i < len(eventIds); i++ {
+				assert.True(t, writtenPositions[int64(i)], "position %d is missing", i)
+			}
+
+			// Each node can still write in the stream, after the others did.
+			for _, node := range nodes {
+				_, err := node.Write(context.Background(), []simplestore.AppendToStream{
+					{
+						Stream: stream,
+						Events: []simplestore.Event{
+							{EventId: uuid.NewString(), EventType: "Bar", Payload: []byte("bar")},
+						},
+					},
+				})
+				assert.Nil(t, err)
+			}
+		})
+	})
+
 	t.Run("eventually consistent topology view does not cause out-of-order writes", func(t *testing.T) {
 		// This scenario is relatively complex but very important to take into account.
 		// Due to the eventual consistency nature of the topology manager, two concurrent writers (w1 and w2) might have
@@ -0,0 +1,33 @@
+// Package tracing holds what the OpenTelemetry instrumentation of the store's layers shares.
+// Spans are exported only when a tracer provider is configured (see `fossil run --tracing`).
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The attributes of the spans.
+const (
+	StreamKey     = attribute.Key("fossil.stream")
+	StreamsKey    = attribute.Key("fossil.streams")
+	PrefixKey     = attribute.Key("fossil.prefix")
+	SegmentIdKey  = attribute.Key("fossil.segment_id")
+	EventCountKey = attribute.Key("fossil.event_count")
+)
+
+// End ends the span, recording the error which made the operation fail, if any.
+func End(span trace.Span, err error) {
+	if err != nil {
+		RecordError(span, err)
+	}
+
+	span.End()
+}
+
+// RecordError records the error on the span, marking its operation as failed.
+func RecordError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}